@@ -18,8 +18,8 @@ func main() {
 			FilePath:      "./logs/async_app.log",
 			MaxFileSizeMB: 10,
 		},
-		Async:        true, // Enable async mode
-		DefaultLevel: logger.LevelInfo,
+		Async:    true, // Enable async mode
+		MinLevel: logger.LevelInfo,
 	}
 
 	asyncFileLm, err := logger.NewLogManager(asyncFileConfig)
@@ -32,6 +32,8 @@ func main() {
 	fmt.Println("✅ Logs written to ./logs/app.log")
 
 	// Example 2: SQL-based logger
+	// Requires a database/sql driver to be registered, e.g.
+	// import _ "github.com/go-sql-driver/mysql"
 	fmt.Println("\n=== SQL Backend Example ===")
 	sqlConfig := logger.Config{
 		Backend: logger.BackendSQL,
@@ -58,7 +60,7 @@ func main() {
 	defaultConfig := logger.Config{
 		Backend:       logger.BackendFile,
 		BackendConfig: logger.DefaultFileConfig(),
-		DefaultLevel:  logger.LevelInfo,
+		MinLevel:      logger.LevelInfo,
 	}
 
 	defaultLm, err := logger.NewLogManager(defaultConfig)
@@ -0,0 +1,149 @@
+// /logger/aggregate.go
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sort"
+	"time"
+)
+
+// AggregateOptions selects how matching entries are grouped and counted
+type AggregateOptions struct {
+	ByLevel    bool          // group by level
+	ByMetadata string        // group by the value of this metadata key
+	Bucket     time.Duration // group by fixed time buckets aligned to the Unix epoch
+
+	TopMessages int // also return the N most frequent messages
+}
+
+// AggregateGroup is the count of entries sharing the grouped values.
+// Fields that are not grouped on are left empty.
+type AggregateGroup struct {
+	Level       LogLevel
+	MetaValue   string // "" when the entry has no value for the key
+	BucketStart time.Time
+	Count       int64
+}
+
+// MessageCount is a message and how often it occurred
+type MessageCount struct {
+	Message string
+	Count   int64
+}
+
+// AggregateResult holds the groups sorted by bucket, level and metadata value
+type AggregateResult struct {
+	Groups      []AggregateGroup
+	TopMessages []MessageCount
+	Total       int64
+}
+
+// AggregatingBackend is implemented by backends that can aggregate natively
+type AggregatingBackend interface {
+	Aggregate(ctx context.Context, level LogLevel, filter LogFilter, opts AggregateOptions) (AggregateResult, error)
+}
+
+func (opts AggregateOptions) validate() error {
+	if opts.Bucket < 0 {
+		return fmt.Errorf("invalid aggregate bucket: %v", opts.Bucket)
+	}
+	if opts.TopMessages < 0 {
+		return fmt.Errorf("invalid aggregate top messages: %d", opts.TopMessages)
+	}
+	return nil
+}
+
+// bucketStart aligns ts to the start of its bucket
+func bucketStart(ts time.Time, bucket time.Duration) time.Time {
+	ns := ts.UnixNano()
+	mod := ns % int64(bucket)
+	if mod < 0 {
+		mod += int64(bucket)
+	}
+	return time.Unix(0, ns-mod)
+}
+
+type groupKey struct {
+	level  LogLevel
+	meta   string
+	bucket int64
+}
+
+// aggregateEntries computes the aggregate in a single pass over entries
+func aggregateEntries(entries iter.Seq2[LogEntry, error], opts AggregateOptions) (AggregateResult, error) {
+	counts := make(map[groupKey]int64)
+	messages := make(map[string]int64)
+	var total int64
+
+	for entry, err := range entries {
+		if err != nil {
+			return AggregateResult{}, err
+		}
+
+		var key groupKey
+		if opts.ByLevel {
+			key.level = entry.Level
+		}
+		if opts.ByMetadata != "" {
+			if v, ok := entry.Metadata[opts.ByMetadata]; ok && v != nil {
+				key.meta = metadataText(v)
+			}
+		}
+		if opts.Bucket > 0 {
+			key.bucket = bucketStart(entry.Timestamp, opts.Bucket).UnixNano()
+		}
+
+		counts[key]++
+		total++
+		if opts.TopMessages > 0 {
+			messages[entry.Message]++
+		}
+	}
+
+	result := AggregateResult{Total: total}
+	for key, count := range counts {
+		group := AggregateGroup{Level: key.level, MetaValue: key.meta, Count: count}
+		if opts.Bucket > 0 {
+			group.BucketStart = time.Unix(0, key.bucket)
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	sortGroups(result.Groups)
+
+	for msg, count := range messages {
+		result.TopMessages = append(result.TopMessages, MessageCount{Message: msg, Count: count})
+	}
+	result.TopMessages = topMessages(result.TopMessages, opts.TopMessages)
+
+	return result, nil
+}
+
+func sortGroups(groups []AggregateGroup) {
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if !a.BucketStart.Equal(b.BucketStart) {
+			return a.BucketStart.Before(b.BucketStart)
+		}
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		return a.MetaValue < b.MetaValue
+	})
+}
+
+// topMessages sorts by count (then message) and keeps the first n
+func topMessages(messages []MessageCount, n int) []MessageCount {
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Count != messages[j].Count {
+			return messages[i].Count > messages[j].Count
+		}
+		return messages[i].Message < messages[j].Message
+	})
+	if len(messages) > n {
+		messages = messages[:n]
+	}
+	return messages
+}
@@ -0,0 +1,98 @@
+// /logger/aggregate_test.go
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAggregateFileBackend(t *testing.T) {
+	lm, err := NewLogManager(Config{
+		Backend:       BackendFile,
+		BackendConfig: FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	lm.WriteLogWithMetadata(LevelError, "disk full", map[string]interface{}{"component": "storage"})
+	lm.WriteLogWithMetadata(LevelError, "disk full", map[string]interface{}{"component": "storage"})
+	lm.WriteLogWithMetadata(LevelError, "timeout", map[string]interface{}{"component": "scheduler"})
+	lm.WriteLog(LevelInfo, "started")
+
+	result, err := lm.Aggregate(context.Background(), "", LogFilter{}, AggregateOptions{
+		ByLevel:     true,
+		ByMetadata:  "component",
+		Bucket:      time.Hour,
+		TopMessages: 1,
+	})
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+
+	if result.Total != 4 {
+		t.Errorf("Expected total 4, got %d", result.Total)
+	}
+
+	var got []string
+	for _, g := range result.Groups {
+		got = append(got, fmt.Sprintf("%s/%s=%d", g.Level, g.MetaValue, g.Count))
+		if g.BucketStart.IsZero() || g.BucketStart.UnixNano()%int64(time.Hour) != 0 {
+			t.Errorf("Bucket start %v is not aligned to the hour", g.BucketStart)
+		}
+	}
+	if fmt.Sprint(got) != "[ERROR/scheduler=1 ERROR/storage=2 INFO/=1]" {
+		t.Errorf("Unexpected groups: %v", got)
+	}
+
+	if len(result.TopMessages) != 1 || result.TopMessages[0] != (MessageCount{Message: "disk full", Count: 2}) {
+		t.Errorf("Unexpected top messages: %+v", result.TopMessages)
+	}
+}
+
+func TestAggregateMetadataMatchesSQL(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 0)
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")}, 0)
+	for _, n := range []interface{}{1000000, 2.5, true} {
+		entry := LogEntry{Level: LevelInfo, Message: "tick", Timestamp: time.Now(), Metadata: map[string]interface{}{"n": n}}
+		fb.Write(entry)
+		sb.Write(entry)
+	}
+
+	opts := AggregateOptions{ByMetadata: "n"}
+	want, err := sb.Aggregate(context.Background(), "", LogFilter{}, opts)
+	if err != nil {
+		t.Fatalf("SQL Aggregate failed: %v", err)
+	}
+	got, err := aggregateEntries(fb.Stream(context.Background(), "", LogFilter{}), opts)
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if fmt.Sprint(got.Groups) != fmt.Sprint(want.Groups) {
+		t.Errorf("file groups = %v, SQL groups = %v", got.Groups, want.Groups)
+	}
+}
+
+func TestSQLBuildAggregate(t *testing.T) {
+	sb := &SQLBackend{config: SQLConfig{TableName: "logs"}, dialect: sqlDialect{driver: "mysql"}}
+
+	query, args := sb.buildAggregate("", LogFilter{MinLevel: LevelError}, AggregateOptions{
+		ByLevel:    true,
+		ByMetadata: "component",
+		Bucket:     time.Minute,
+	})
+
+	want := "SELECT level, COALESCE(JSON_UNQUOTE(JSON_EXTRACT(metadata, ?)), ''), (ts DIV 60000000000) * 60000000000, COUNT(*) " +
+		"FROM logs WHERE level IN (?, ?, ?) GROUP BY 1, 2, 3"
+	if query != want {
+		t.Errorf("Unexpected query:\n got: %s\nwant: %s", query, want)
+	}
+	if fmt.Sprint(args) != `[$."component" ERROR FATAL PANIC]` {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
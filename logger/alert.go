@@ -0,0 +1,361 @@
+// /logger/alert.go
+
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AlertCondition selects how a rule is evaluated
+type AlertCondition string
+
+const (
+	// AlertThreshold fires when more than Threshold entries match within Window
+	AlertThreshold AlertCondition = "threshold"
+	// AlertAbsence fires when no entry matches for Window
+	AlertAbsence AlertCondition = "absence"
+)
+
+// AlertRule describes one alert
+type AlertRule struct {
+	Name      string
+	Filter    LogFilter // paging fields are ignored
+	Condition AlertCondition
+	Threshold int           // threshold rules: matches allowed within Window
+	Window    time.Duration // sliding window, or the allowed silence for absence rules
+	Cooldown  time.Duration // minimum time between two firing notifications
+}
+
+// AlertState is the state of a rule
+type AlertState int
+
+const (
+	AlertResolved AlertState = iota // condition not met
+	AlertFiring                     // condition met and notified
+)
+
+func (s AlertState) String() string {
+	switch s {
+	case AlertResolved:
+		return "resolved"
+	case AlertFiring:
+		return "firing"
+	}
+	return "unknown"
+}
+
+// Alert is sent to notifiers when a rule starts firing or resolves
+type Alert struct {
+	Rule     string
+	State    AlertState
+	Count    int       // matches within the window (threshold rules)
+	StartsAt time.Time // when the rule started firing
+	At       time.Time // time of the notification
+	Message  string
+}
+
+// Notifier delivers alerts
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// NotifierFunc adapts a function to Notifier
+type NotifierFunc func(alert Alert) error
+
+func (f NotifierFunc) Notify(alert Alert) error {
+	return f(alert)
+}
+
+// NewWriterNotifier writes one line per alert to w
+func NewWriterNotifier(w io.Writer) Notifier {
+	return NotifierFunc(func(a Alert) error {
+		_, err := fmt.Fprintf(w, "[%s] %s %s: %s\n", a.At.Format(time.RFC3339), a.State, a.Rule, a.Message)
+		return err
+	})
+}
+
+// AlertOption configures an AlertEngine
+type AlertOption func(*AlertEngine)
+
+// WithAlertClock replaces time.Now, mainly for tests
+func WithAlertClock(now func() time.Time) AlertOption {
+	return func(e *AlertEngine) {
+		e.now = now
+	}
+}
+
+// WithAlertErrorHandler receives notifier errors; by default they are written
+// to standard error
+func WithAlertErrorHandler(fn func(rule string, err error)) AlertOption {
+	return func(e *AlertEngine) {
+		e.onError = fn
+	}
+}
+
+// AlertEngine is a LogHandler evaluating alert rules. Matches are timed with
+// the engine clock rather than the entry timestamp. Entries drive threshold
+// rules directly; absence rules and resolving need Evaluate, which Run calls
+// periodically.
+type AlertEngine struct {
+	mu       sync.Mutex
+	notifyMu sync.Mutex // keeps notifications in order
+	rules    []*alertRuleState
+
+	notifiers []Notifier
+	now       func() time.Time
+	onError   func(rule string, err error)
+}
+
+type alertRuleState struct {
+	AlertRule
+	matcher   *entryMatcher
+	matches   []time.Time // threshold rules, oldest first
+	lastSeen  time.Time   // absence rules
+	state     AlertState
+	startsAt  time.Time
+	lastFired time.Time
+}
+
+// NewAlertEngine validates the rules and creates an engine notifying the
+// given notifiers
+func NewAlertEngine(rules []AlertRule, notifiers []Notifier, opts ...AlertOption) (*AlertEngine, error) {
+	e := &AlertEngine{notifiers: notifiers, now: time.Now}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.onError == nil {
+		e.onError = func(rule string, err error) {
+			fmt.Fprintf(os.Stderr, "alert %s notify error: %v\n", rule, err)
+		}
+	}
+
+	start := e.now()
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, errors.New("alert rule without name")
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate alert rule: %s", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		switch rule.Condition {
+		case AlertThreshold, AlertAbsence:
+		default:
+			return nil, fmt.Errorf("alert %s: unknown condition: %q", rule.Name, rule.Condition)
+		}
+		if rule.Window <= 0 {
+			return nil, fmt.Errorf("alert %s: window must be positive", rule.Name)
+		}
+		if rule.Threshold < 0 || rule.Cooldown < 0 {
+			return nil, fmt.Errorf("alert %s: threshold and cooldown must not be negative", rule.Name)
+		}
+
+		matcher, err := newEntryMatcher("", rule.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("alert %s: %w", rule.Name, err)
+		}
+		e.rules = append(e.rules, &alertRuleState{AlertRule: rule, matcher: matcher, lastSeen: start})
+	}
+	return e, nil
+}
+
+// Handle implements LogHandler
+func (e *AlertEngine) Handle(entry LogEntry) error {
+	e.mu.Lock()
+	now := e.now()
+	var alerts []Alert
+	for _, r := range e.rules {
+		if !r.matcher.match(entry) {
+			continue
+		}
+		switch r.Condition {
+		case AlertThreshold:
+			r.matches = append(r.matches, now)
+			if a, ok := r.evaluate(now); ok {
+				alerts = append(alerts, a)
+			}
+		case AlertAbsence:
+			r.lastSeen = now
+			if r.state == AlertFiring {
+				alerts = append(alerts, r.resolve(now, "matching entry seen"))
+			}
+		}
+	}
+	e.notify(alerts)
+	return nil
+}
+
+// Evaluate checks every rule at the current time
+func (e *AlertEngine) Evaluate() {
+	e.mu.Lock()
+	now := e.now()
+	var alerts []Alert
+	for _, r := range e.rules {
+		if a, ok := r.evaluate(now); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	e.notify(alerts)
+}
+
+// Run calls Evaluate every interval until ctx is cancelled
+func (e *AlertEngine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate()
+		}
+	}
+}
+
+// Status returns the current state of every rule, in rule order
+func (e *AlertEngine) Status() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	status := make([]Alert, 0, len(e.rules))
+	for _, r := range e.rules {
+		r.prune(now)
+		status = append(status, Alert{
+			Rule:     r.Name,
+			State:    r.state,
+			Count:    len(r.matches),
+			StartsAt: r.startsAt,
+			At:       now,
+		})
+	}
+	return status
+}
+
+// notify must be called with e.mu held; it unlocks it before calling notifiers
+func (e *AlertEngine) notify(alerts []Alert) {
+	e.notifyMu.Lock()
+	e.mu.Unlock()
+	defer e.notifyMu.Unlock()
+
+	for _, a := range alerts {
+		for _, n := range e.notifiers {
+			if err := n.Notify(a); err != nil {
+				e.onError(a.Rule, err)
+			}
+		}
+	}
+}
+
+// prune drops matches that left the window
+func (r *alertRuleState) prune(now time.Time) {
+	cutoff := now.Add(-r.Window)
+	i := 0
+	for i < len(r.matches) && !r.matches[i].After(cutoff) {
+		i++
+	}
+	r.matches = r.matches[i:]
+}
+
+// evaluate returns the alert to send, if the state changes
+func (r *alertRuleState) evaluate(now time.Time) (Alert, bool) {
+	var met bool
+	var msg string
+	switch r.Condition {
+	case AlertThreshold:
+		r.prune(now)
+		met = len(r.matches) > r.Threshold
+		msg = fmt.Sprintf("%d matching entries within %v (threshold %d)", len(r.matches), r.Window, r.Threshold)
+	case AlertAbsence:
+		silence := now.Sub(r.lastSeen)
+		met = silence >= r.Window
+		msg = fmt.Sprintf("no matching entry for %v", silence.Truncate(time.Second))
+	}
+
+	switch {
+	case met && r.state == AlertResolved:
+		// Deduplicate re-fires within the cooldown
+		if !r.lastFired.IsZero() && now.Sub(r.lastFired) < r.Cooldown {
+			return Alert{}, false
+		}
+		r.state = AlertFiring
+		r.startsAt = now
+		r.lastFired = now
+		return Alert{Rule: r.Name, State: AlertFiring, Count: len(r.matches), StartsAt: now, At: now, Message: msg}, true
+	case !met && r.state == AlertFiring:
+		return r.resolve(now, msg), true
+	}
+	return Alert{}, false
+}
+
+func (r *alertRuleState) resolve(now time.Time, msg string) Alert {
+	r.state = AlertResolved
+	return Alert{Rule: r.Name, State: AlertResolved, Count: len(r.matches), StartsAt: r.startsAt, At: now, Message: msg}
+}
+
+// alertRuleConfig is the JSON form of an AlertRule
+type alertRuleConfig struct {
+	Name      string `json:"name"`
+	Query     string `json:"query"`
+	Condition string `json:"condition"`
+	Threshold int    `json:"threshold"`
+	Window    string `json:"window"`
+	Cooldown  string `json:"cooldown"`
+}
+
+// LoadAlertRules reads rules from JSON of the form
+//
+//	{"rules": [{"name": "storage-errors", "query": "level >= ERROR and meta.component = storage",
+//	            "condition": "threshold", "threshold": 20, "window": "5m", "cooldown": "15m"}]}
+//
+// Queries use the ParseFilter syntax; durations use time.ParseDuration.
+func LoadAlertRules(r io.Reader) ([]AlertRule, error) {
+	var config struct {
+		Rules []alertRuleConfig `json:"rules"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to decode alert rules: %w", err)
+	}
+
+	rules := make([]AlertRule, 0, len(config.Rules))
+	for _, c := range config.Rules {
+		rule := AlertRule{Name: c.Name, Condition: AlertCondition(c.Condition), Threshold: c.Threshold}
+
+		if c.Query != "" {
+			filter, err := ParseFilter(c.Query)
+			if err != nil {
+				return nil, fmt.Errorf("alert %s: %w", c.Name, err)
+			}
+			rule.Filter = filter
+		}
+
+		var err error
+		if rule.Window, err = parseOptionalDuration(c.Window); err != nil {
+			return nil, fmt.Errorf("alert %s: invalid window: %w", c.Name, err)
+		}
+		if rule.Cooldown, err = parseOptionalDuration(c.Cooldown); err != nil {
+			return nil, fmt.Errorf("alert %s: invalid cooldown: %w", c.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,160 @@
+// /logger/alert_test.go
+
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+type alertRecorder struct {
+	alerts []Alert
+}
+
+func (r *alertRecorder) Notify(a Alert) error {
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func (r *alertRecorder) states() string {
+	var s []string
+	for _, a := range r.alerts {
+		s = append(s, a.Rule+":"+a.State.String())
+	}
+	return strings.Join(s, ",")
+}
+
+func newTestAlertEngine(t *testing.T, rules ...AlertRule) (*AlertEngine, *fakeClock, *alertRecorder) {
+	t.Helper()
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	rec := &alertRecorder{}
+	e, err := NewAlertEngine(rules, []Notifier{rec}, WithAlertClock(clock.now))
+	if err != nil {
+		t.Fatalf("NewAlertEngine failed: %v", err)
+	}
+	return e, clock, rec
+}
+
+func TestAlertThreshold(t *testing.T) {
+	e, clock, rec := newTestAlertEngine(t, AlertRule{
+		Name:      "errors",
+		Filter:    LogFilter{Levels: []LogLevel{LevelError}},
+		Condition: AlertThreshold,
+		Threshold: 2,
+		Window:    time.Minute,
+		Cooldown:  10 * time.Minute,
+	})
+	errEntry := LogEntry{Level: LevelError, Message: "boom"}
+
+	e.Handle(errEntry)
+	e.Handle(LogEntry{Level: LevelInfo, Message: "fine"})
+	e.Handle(errEntry)
+	if len(rec.alerts) != 0 {
+		t.Fatalf("fired at threshold: %s", rec.states())
+	}
+
+	e.Handle(errEntry)
+	e.Handle(errEntry) // deduplicated while firing
+	if rec.states() != "errors:firing" || rec.alerts[0].Count != 3 {
+		t.Fatalf("alerts = %s (%+v)", rec.states(), rec.alerts)
+	}
+
+	clock.advance(2 * time.Minute)
+	e.Evaluate()
+	if rec.states() != "errors:firing,errors:resolved" {
+		t.Fatalf("alerts = %s", rec.states())
+	}
+
+	// Condition met again within the cooldown: suppressed until it expires
+	for i := 0; i < 3; i++ {
+		e.Handle(errEntry)
+	}
+	if len(rec.alerts) != 2 {
+		t.Fatalf("fired during cooldown: %s", rec.states())
+	}
+	clock.advance(9 * time.Minute)
+	for i := 0; i < 3; i++ {
+		e.Handle(errEntry)
+	}
+	if rec.states() != "errors:firing,errors:resolved,errors:firing" {
+		t.Fatalf("alerts = %s", rec.states())
+	}
+	if st := e.Status(); st[0].State != AlertFiring || st[0].Count != 3 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestAlertAbsence(t *testing.T) {
+	e, clock, rec := newTestAlertEngine(t, AlertRule{
+		Name:      "heartbeat",
+		Filter:    LogFilter{Contains: "heartbeat"},
+		Condition: AlertAbsence,
+		Window:    10 * time.Minute,
+	})
+
+	clock.advance(5 * time.Minute)
+	e.Handle(LogEntry{Level: LevelInfo, Message: "heartbeat"})
+	clock.advance(9 * time.Minute)
+	e.Evaluate()
+	if len(rec.alerts) != 0 {
+		t.Fatalf("fired early: %s", rec.states())
+	}
+
+	clock.advance(time.Minute)
+	e.Evaluate()
+	e.Evaluate()
+	if rec.states() != "heartbeat:firing" {
+		t.Fatalf("alerts = %s", rec.states())
+	}
+
+	e.Handle(LogEntry{Level: LevelInfo, Message: "heartbeat"})
+	if rec.states() != "heartbeat:firing,heartbeat:resolved" {
+		t.Fatalf("alerts = %s", rec.states())
+	}
+}
+
+func TestLoadAlertRules(t *testing.T) {
+	rules, err := LoadAlertRules(strings.NewReader(`{"rules": [
+		{"name": "storage", "query": "level >= ERROR and meta.component = storage",
+		 "condition": "threshold", "threshold": 20, "window": "5m", "cooldown": "15m"},
+		{"name": "heartbeat", "query": "msg ~ heartbeat", "condition": "absence", "window": "10m"}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadAlertRules failed: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Window != 5*time.Minute || rules[0].Cooldown != 15*time.Minute ||
+		rules[0].Threshold != 20 || rules[1].Condition != AlertAbsence || rules[0].Filter.Expr == nil {
+		t.Fatalf("rules = %+v", rules)
+	}
+	if _, err := NewAlertEngine(rules, nil); err != nil {
+		t.Fatalf("NewAlertEngine failed: %v", err)
+	}
+
+	for _, bad := range []string{
+		`{"rules": [{"name": "x", "query": "level >", "condition": "absence", "window": "1m"}]}`,
+		`{"rules": [{"name": "x", "condition": "absence", "window": "soon"}]}`,
+		`{"rules": [{"name": "x", "unknown": 1}]}`,
+	} {
+		if _, err := LoadAlertRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+
+	for _, rule := range []AlertRule{
+		{Name: "x", Condition: "sometimes", Window: time.Minute},
+		{Name: "x", Condition: AlertAbsence},
+		{Condition: AlertAbsence, Window: time.Minute},
+	} {
+		if _, err := NewAlertEngine([]AlertRule{rule}, nil); err == nil {
+			t.Errorf("expected error for %+v", rule)
+		}
+	}
+}
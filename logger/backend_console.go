@@ -0,0 +1,201 @@
+// /logger/backend_console.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ColorMode controls ANSI colors in console output
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = ""       // color terminals unless NO_COLOR is set
+	ColorAlways ColorMode = "always" // also when redirected or NO_COLOR is set
+	ColorNever  ColorMode = "never"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiGray  = "\x1b[90m"
+)
+
+// levelColors are the ANSI colors per level; other levels are not colored
+var levelColors = map[LogLevel]string{
+	LevelTrace: "\x1b[90m",
+	LevelDebug: "\x1b[90m",
+	LevelInfo:  "\x1b[36m",
+	LevelWarn:  "\x1b[33m",
+	LevelError: "\x1b[31m",
+	LevelFatal: "\x1b[1;31m",
+	LevelPanic: "\x1b[1;35m",
+}
+
+// ConsoleBackend writes human friendly lines to the terminal. It is write
+// only: Read and ClearLogs return ErrNotSupported.
+type ConsoleBackend struct {
+	mu       sync.Mutex
+	config   ConsoleConfig
+	stderrAt int // severity routed to ErrorOutput, 0 when disabled
+	color    bool
+	errColor bool
+}
+
+func (cb *ConsoleBackend) Init(config interface{}) error {
+	consoleConfig, ok := config.(ConsoleConfig)
+	if !ok {
+		return fmt.Errorf("invalid config type for console backend")
+	}
+
+	switch consoleConfig.Color {
+	case ColorAuto, ColorAlways, ColorNever:
+	default:
+		return fmt.Errorf("invalid console color mode: %q", consoleConfig.Color)
+	}
+	if consoleConfig.StderrLevel != "" {
+		sev, ok := consoleConfig.StderrLevel.Severity()
+		if !ok {
+			return fmt.Errorf("unknown console stderr level: %s", consoleConfig.StderrLevel)
+		}
+		cb.stderrAt = sev
+	}
+	if consoleConfig.Output == nil {
+		consoleConfig.Output = os.Stdout
+	}
+	if consoleConfig.ErrorOutput == nil {
+		consoleConfig.ErrorOutput = os.Stderr
+	}
+	if consoleConfig.TimeFormat == "" {
+		consoleConfig.TimeFormat = "15:04:05.000"
+	}
+	if consoleConfig.MessageWidth <= 0 {
+		consoleConfig.MessageWidth = 40
+	}
+
+	cb.config = consoleConfig
+	cb.color = useColor(consoleConfig.Color, consoleConfig.Output)
+	cb.errColor = useColor(consoleConfig.Color, consoleConfig.ErrorOutput)
+	return nil
+}
+
+// useColor applies the color mode to w
+func useColor(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (cb *ConsoleBackend) Write(entry LogEntry) error {
+	out, color := cb.config.Output, cb.color
+	if sev, ok := entry.Level.Severity(); ok && cb.stderrAt > 0 && sev >= cb.stderrAt {
+		out, color = cb.config.ErrorOutput, cb.errColor
+	}
+
+	line := cb.format(entry, color)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if _, err := io.WriteString(out, line); err != nil {
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+	return nil
+}
+
+// format renders one line: time, padded level, message and sorted metadata
+func (cb *ConsoleBackend) format(entry LogEntry, color bool) string {
+	paint := func(code, s string) string {
+		if !color || code == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var b strings.Builder
+	b.WriteString(paint(ansiGray, entry.Timestamp.Format(cb.config.TimeFormat)))
+	b.WriteByte(' ')
+	b.WriteString(paint(levelColors[entry.Level], fmt.Sprintf("%-5s", entry.Level)))
+	b.WriteByte(' ')
+	b.WriteString(entry.Message)
+
+	if len(entry.Metadata) > 0 {
+		if pad := cb.config.MessageWidth - len([]rune(entry.Message)); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+
+		keys := make([]string, 0, len(entry.Metadata))
+		for k := range entry.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			b.WriteByte(' ')
+			b.WriteString(paint(ansiDim, k+"="))
+			b.WriteString(consoleValue(entry.Metadata[k]))
+		}
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// consoleValue prints strings bare unless they need quoting, and other
+// values as JSON
+func consoleValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	case error:
+		return fmt.Sprintf("%q", v.Error())
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return consoleValue(v.String())
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func (cb *ConsoleBackend) Read(level LogLevel, filter LogFilter) ([]LogEntry, error) {
+	return nil, fmt.Errorf("console backend cannot read logs: %w", ErrNotSupported)
+}
+
+func (cb *ConsoleBackend) ClearLogs(before time.Time) error {
+	return fmt.Errorf("console backend cannot clear logs: %w", ErrNotSupported)
+}
+
+// Close leaves the outputs open; they usually are stdout and stderr
+func (cb *ConsoleBackend) Close() error {
+	return nil
+}
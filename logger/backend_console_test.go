@@ -0,0 +1,84 @@
+// /logger/backend_console_test.go
+
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleFormat(t *testing.T) {
+	var out, errOut bytes.Buffer
+	cb := &ConsoleBackend{}
+	err := cb.Init(ConsoleConfig{
+		Output:       &out,
+		ErrorOutput:  &errOut,
+		StderrLevel:  LevelError,
+		Color:        ColorNever,
+		MessageWidth: 12,
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	ts := time.Date(2024, 1, 1, 9, 5, 7, 250e6, time.UTC)
+	cb.Write(LogEntry{Level: LevelInfo, Message: "started", Timestamp: ts,
+		Metadata: map[string]interface{}{"port": 8080, "name": "api server", "tags": []string{"a"}}})
+	cb.Write(LogEntry{Level: LevelWarn, Message: "slow", Timestamp: ts})
+	cb.Write(LogEntry{Level: LevelError, Message: "failed", Timestamp: ts})
+
+	want := "09:05:07.250 INFO  started      name=\"api server\" port=8080 tags=[\"a\"]\n" +
+		"09:05:07.250 WARN  slow\n"
+	if out.String() != want {
+		t.Errorf("stdout =\n%q\nwant\n%q", out.String(), want)
+	}
+	if errOut.String() != "09:05:07.250 ERROR failed\n" {
+		t.Errorf("stderr = %q", errOut.String())
+	}
+}
+
+func TestConsoleColor(t *testing.T) {
+	var out bytes.Buffer
+	cb := &ConsoleBackend{}
+	cb.Init(ConsoleConfig{Output: &out, Color: ColorAlways})
+	cb.Write(LogEntry{Level: LevelError, Message: "boom", Timestamp: time.Now()})
+	if !strings.Contains(out.String(), levelColors[LevelError]+"ERROR"+ansiReset) {
+		t.Errorf("expected colored level in %q", out.String())
+	}
+
+	// Auto mode never colors buffers, pipes or NO_COLOR terminals
+	if useColor(ColorAuto, &out) {
+		t.Error("expected no color for a buffer")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if useColor(ColorAuto, w) {
+		t.Error("expected no color for a pipe")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if useColor(ColorAuto, os.Stdout) {
+		t.Error("expected NO_COLOR to disable colors")
+	}
+	if !useColor(ColorAlways, w) {
+		t.Error("expected ColorAlways to force colors")
+	}
+}
+
+func TestConsoleInvalidConfig(t *testing.T) {
+	for _, config := range []interface{}{
+		FileConfig{},
+		ConsoleConfig{Color: "rainbow"},
+		ConsoleConfig{StderrLevel: "LOUD"},
+	} {
+		if err := (&ConsoleBackend{}).Init(config); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
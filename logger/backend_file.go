@@ -4,18 +4,26 @@ package logger
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type FileBackend struct {
-	mu     sync.Mutex
-	config FileConfig
-	file   *os.File
+	mu      sync.Mutex
+	config  FileConfig
+	format  Format
+	file    *os.File
+	written uint64 // bytes appended since Init, guarded by mu
+
+	malformed atomic.Uint64 // lines skipped by reads
 }
 
 func (fb *FileBackend) Init(config interface{}) error {
@@ -24,7 +32,26 @@ func (fb *FileBackend) Init(config interface{}) error {
 		return fmt.Errorf("invalid config type for file backend")
 	}
 
+	name := fileConfig.Format
+	if name == "" {
+		name = FormatText
+	}
+	format, ok := LookupFormat(name)
+	if !ok {
+		return fmt.Errorf("unknown log format: %s", name)
+	}
+	if fileConfig.Layout != "" {
+		if name != FormatText {
+			return fmt.Errorf("layout requires the text format, not %s", name)
+		}
+		var err error
+		if format, err = NewLayoutFormat(fileConfig.Layout); err != nil {
+			return fmt.Errorf("invalid layout: %w", err)
+		}
+	}
+
 	fb.config = fileConfig
+	fb.format = format
 
 	// Create directory if needed
 	dir := filepath.Dir(fileConfig.FilePath)
@@ -50,111 +77,273 @@ func (fb *FileBackend) Write(entry LogEntry) error {
 		return fmt.Errorf("file backend not initialized")
 	}
 
-	timestamp := entry.Timestamp.Format(time.RFC3339)
-	line := fmt.Sprintf("[%s] %-5s: %s\n", timestamp, entry.Level, entry.Message)
+	data, err := fb.format.Encoder.Encode(entry)
+	if err != nil {
+		return err
+	}
+	line := string(data) + "\n"
 
-	if _, err := fb.file.WriteString(line); err != nil {
+	n, err := fb.file.WriteString(line)
+	fb.written += uint64(n)
+	if err != nil {
 		return fmt.Errorf("failed to write log: %w", err)
 	}
 
 	return nil
 }
 
-// ✅ Helper: apply LogFilter (struct-based filter)
-func applyFilter(entry LogEntry, filter LogFilter) bool {
-	// Filter by keyword (Contains)
-	if filter.Contains != "" && !strings.Contains(entry.Message, filter.Contains) {
-		return false
+// ✅ Full implementation of Read()
+func (fb *FileBackend) Read(level LogLevel, filter LogFilter) ([]LogEntry, error) {
+	page, err := fb.ReadPage(level, filter)
+	if err != nil {
+		return nil, err
 	}
+	return page.Entries, nil
+}
 
-	// Filter: StartTime (entry must be AFTER start)
-	if filter.StartTime != nil && entry.Timestamp.Before(*filter.StartTime) {
-		return false
+// ReadPage reads one page of logs. Cursors are byte offsets into the file,
+// and descending pages are read by scanning backward from the end of the file.
+func (fb *FileBackend) ReadPage(level LogLevel, filter LogFilter) (LogPage, error) {
+	m, err := newEntryMatcher(level, filter)
+	if err != nil {
+		return LogPage{}, err
 	}
 
-	// Filter: EndTime (entry must be BEFORE end)
-	if filter.EndTime != nil && entry.Timestamp.After(*filter.EndTime) {
-		return false
+	rf, size, err := fb.openReader()
+	if err != nil {
+		return LogPage{}, err
 	}
+	defer rf.Close()
 
-	return true
+	return readPage(filter, func(from *pageCursor, forward bool, max int, visit func(pageHit) bool) error {
+		var decodeErr error
+		err := scanLines(rf, size, from, forward, func(line []byte, start, next int64) bool {
+			entry, ok, err := fb.matchLine(line, start, m)
+			if err != nil {
+				decodeErr = err
+				return false
+			}
+			if !ok {
+				return true
+			}
+
+			return visit(pageHit{
+				entry:  entry,
+				before: pageCursor{Offset: start},
+				after:  pageCursor{Offset: next, Forward: true},
+			})
+		})
+		if decodeErr != nil {
+			return decodeErr
+		}
+		return err
+	})
 }
 
-// ✅ Full implementation of Read()
-func (fb *FileBackend) Read(level LogLevel, filter LogFilter) ([]LogEntry, error) {
+// Stream reads matching logs one line at a time, honoring Order, Cursor and
+// Limit. The read handle is closed when iteration stops.
+func (fb *FileBackend) Stream(ctx context.Context, level LogLevel, filter LogFilter) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		from, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+
+		m, err := newEntryMatcher(level, filter)
+		if err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+
+		rf, size, err := fb.openReader()
+		if err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+		defer rf.Close()
+
+		forward := filter.Order != OrderDesc
+		if from != nil {
+			forward = from.Forward
+		}
+
+		count := 0
+		stopped := false
+		err = scanLines(rf, size, from, forward, func(line []byte, start, next int64) bool {
+			if err := ctx.Err(); err != nil {
+				yield(LogEntry{}, err)
+				stopped = true
+				return false
+			}
+
+			entry, ok, err := fb.matchLine(line, start, m)
+			if err != nil {
+				yield(LogEntry{}, err)
+				stopped = true
+				return false
+			}
+			if !ok {
+				return true
+			}
+
+			if !yield(entry, nil) {
+				stopped = true
+				return false
+			}
+
+			count++
+			return filter.Limit <= 0 || count < filter.Limit
+		})
+		if err != nil && !stopped {
+			yield(LogEntry{}, err)
+		}
+	}
+}
+
+// decodeLine decodes the line starting at offset. Malformed lines are
+// counted, reported to OnMalformedLine and skipped, or returned as an error
+// when the backend is strict.
+func (fb *FileBackend) decodeLine(line []byte, offset int64) (LogEntry, bool, error) {
+	entry, err := fb.format.Decoder.Decode(line)
+	if err == nil {
+		return entry, true, nil
+	}
+
+	malformed := &MalformedLineError{Offset: offset, Line: string(line), Err: err}
+	if fb.config.Strict {
+		return LogEntry{}, false, malformed
+	}
+	fb.malformed.Add(1)
+	if fb.config.OnMalformedLine != nil {
+		fb.config.OnMalformedLine(malformed)
+	}
+	return LogEntry{}, false, nil
+}
+
+// matchLine decodes line and reports whether it passes the matcher
+func (fb *FileBackend) matchLine(line []byte, offset int64, m *entryMatcher) (LogEntry, bool, error) {
+	entry, ok, err := fb.decodeLine(line, offset)
+	if !ok || !m.match(entry) {
+		return LogEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// openReader opens a new read handle (fb.file is write-only) and returns the
+// current file size. Only lines that were complete at that size are read.
+func (fb *FileBackend) openReader() (*os.File, int64, error) {
 	fb.mu.Lock()
 	defer fb.mu.Unlock()
 
 	if fb.file == nil {
-		return nil, fmt.Errorf("file backend not initialized")
+		return nil, 0, fmt.Errorf("file backend not initialized")
 	}
 
-	// Must open a NEW reader (fb.file is write-only)
 	rf, err := os.Open(fb.config.FilePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file for reading: %w", err)
+		return nil, 0, fmt.Errorf("failed to open file for reading: %w", err)
 	}
-	defer rf.Close()
 
-	scanner := bufio.NewScanner(rf)
-	var results []LogEntry
+	info, err := rf.Stat()
+	if err != nil {
+		rf.Close()
+		return nil, 0, fmt.Errorf("failed to stat log file: %w", err)
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) == 0 {
-			continue
-		}
+	return rf, info.Size(), nil
+}
 
-		// Expected format:
-		// [2025-01-01T12:00:00Z] INFO : message
-		if !strings.HasPrefix(line, "[") {
-			continue
+// scanLines calls fn for every non-empty line of f within [0, size), starting
+// at the boundary from (nil means the beginning, or the end when scanning
+// backward). start is the offset of the line and next the offset following
+// its newline. Scanning stops when fn returns false.
+func scanLines(f *os.File, size int64, from *pageCursor, forward bool, fn func(line []byte, start, next int64) bool) error {
+	if forward {
+		var offset int64
+		if from != nil {
+			offset = from.Offset
 		}
+		return scanLinesForward(f, offset, size, fn)
+	}
 
-		end := strings.Index(line, "]")
-		if end == -1 {
-			continue
-		}
+	end := size
+	if from != nil && from.Offset < size {
+		end = from.Offset
+	}
+	return scanLinesBackward(f, end, fn)
+}
+
+func scanLinesForward(f *os.File, offset, size int64, fn func(line []byte, start, next int64) bool) error {
+	if offset < 0 || offset >= size {
+		return nil
+	}
 
-		tsStr := line[1:end]
-		ts, err := time.Parse(time.RFC3339, tsStr)
+	reader := bufio.NewReader(io.NewSectionReader(f, offset, size-offset))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			start := offset
+			offset += int64(len(line))
+			content := bytes.TrimRight(line, "\r\n")
+			if len(content) > 0 && !fn(content, start, offset) {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
 		if err != nil {
-			continue
+			return err
 		}
+	}
+}
 
-		rest := strings.TrimSpace(line[end+1:])
-		parts := strings.SplitN(rest, ":", 2)
-		if len(parts) != 2 {
-			continue
+func scanLinesBackward(f *os.File, end int64, fn func(line []byte, start, next int64) bool) error {
+	const chunkSize = 64 * 1024
+
+	// buf holds the bytes [pos, pos+len(buf)) that have not been visited yet
+	pos := end
+	var buf []byte
+
+	for {
+		for {
+			contentEnd := len(buf)
+			if contentEnd > 0 && buf[contentEnd-1] == '\n' {
+				contentEnd--
+			}
+			i := bytes.LastIndexByte(buf[:contentEnd], '\n')
+			if i < 0 {
+				break
+			}
+			content := bytes.TrimRight(buf[i+1:contentEnd], "\r")
+			if len(content) > 0 && !fn(content, pos+int64(i+1), pos+int64(len(buf))) {
+				return nil
+			}
+			buf = buf[:i+1]
 		}
 
-		levelStr := strings.TrimSpace(parts[0])
-		msg := strings.TrimSpace(parts[1])
-
-		entry := LogEntry{
-			Timestamp: ts,
-			Level:     LogLevel(levelStr),
-			Message:   msg,
+		if pos <= 0 {
+			content := bytes.TrimRight(buf, "\r\n")
+			if len(content) > 0 {
+				fn(content, 0, int64(len(buf)))
+			}
+			return nil
 		}
 
-		// Filter by level
-		if level != "" && entry.Level != level {
-			continue
+		n := int64(chunkSize)
+		if n > pos {
+			n = pos
 		}
+		pos -= n
 
-		// Apply struct-based filter
-		if !applyFilter(entry, filter) {
-			continue
+		chunk := make([]byte, int(n)+len(buf))
+		if _, err := f.ReadAt(chunk[:n], pos); err != nil && err != io.EOF {
+			return err
 		}
-
-		results = append(results, entry)
+		copy(chunk[n:], buf)
+		buf = chunk
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return results, nil
 }
 
 // ✅ Full implementation of ClearLogs(before)
@@ -166,38 +355,91 @@ func (fb *FileBackend) ClearLogs(before time.Time) error {
 		return fmt.Errorf("file backend not initialized")
 	}
 
-	// Read all logs (no filter, no level)
-	logs, err := fb.Read("", LogFilter{})
+	rf, err := os.Open(fb.config.FilePath)
+	if err != nil {
+		return fmt.Errorf("clear logs failed: %w", err)
+	}
+	defer rf.Close()
+
+	info, err := rf.Stat()
 	if err != nil {
 		return fmt.Errorf("clear logs failed: %w", err)
 	}
 
-	// Keep only logs newer than `before`
-	var kept []LogEntry
-	for _, e := range logs {
-		if e.Timestamp.After(before) {
-			kept = append(kept, e)
+	// Keep only lines of logs newer than `before`
+	var kept bytes.Buffer
+	var decodeErr error
+	err = scanLinesForward(rf, 0, info.Size(), func(line []byte, start, next int64) bool {
+		e, ok, err := fb.decodeLine(line, start)
+		if err != nil {
+			decodeErr = err
+			return false
 		}
+		if ok && e.Timestamp.After(before) {
+			kept.Write(line)
+			kept.WriteByte('\n')
+		}
+		return true
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return fmt.Errorf("clear logs failed: %w", err)
 	}
 
 	// Truncate file
 	fb.file.Close()
-	f, err := os.OpenFile(fb.config.FilePath, os.O_TRUNC|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(fb.config.FilePath, os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
+		fb.file = nil
 		return fmt.Errorf("failed to truncate log file: %w", err)
 	}
 	fb.file = f
 
 	// Rewrite logs
-	for _, e := range kept {
-		if err := fb.Write(e); err != nil {
-			return err
-		}
+	if _, err := fb.file.Write(kept.Bytes()); err != nil {
+		return fmt.Errorf("failed to rewrite log file: %w", err)
 	}
 
 	return nil
 }
 
+// MalformedLines returns the number of lines reads skipped since Init; a
+// line is counted by every read that skips it
+func (fb *FileBackend) MalformedLines() uint64 {
+	return fb.malformed.Load()
+}
+
+// BytesWritten returns the number of bytes appended since Init
+func (fb *FileBackend) BytesWritten() uint64 {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	return fb.written
+}
+
+// Ping checks that the log file is open and still present at its path
+func (fb *FileBackend) Ping(ctx context.Context) error {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	if fb.file == nil {
+		return fmt.Errorf("log file is not open")
+	}
+	open, err := fb.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	onDisk, err := os.Stat(fb.config.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	if !os.SameFile(open, onDisk) {
+		return fmt.Errorf("log file %s was replaced or removed", fb.config.FilePath)
+	}
+	return nil
+}
+
 func (fb *FileBackend) Close() error {
 	fb.mu.Lock()
 	defer fb.mu.Unlock()
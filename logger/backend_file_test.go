@@ -0,0 +1,164 @@
+// /logger/backend_file_test.go
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func messages(entries []LogEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Message
+	}
+	return out
+}
+
+func expectMessages(t *testing.T, entries []LogEntry, want ...string) {
+	t.Helper()
+	got := messages(entries)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestFileBackendPagingAscending(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 5)
+
+	page, err := fb.ReadPage("", LogFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 0", "msg 1")
+	if page.PrevCursor != "" {
+		t.Error("First page should not have a previous cursor")
+	}
+
+	page, err = fb.ReadPage("", LogFilter{Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 2", "msg 3")
+
+	last, err := fb.ReadPage("", LogFilter{Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, last.Entries, "msg 4")
+	if last.NextCursor != "" {
+		t.Error("Last page should not have a next cursor")
+	}
+
+	back, err := fb.ReadPage("", LogFilter{Limit: 2, Cursor: last.PrevCursor})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, back.Entries, "msg 2", "msg 3")
+}
+
+func TestFileBackendPagingDescending(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 5)
+
+	page, err := fb.ReadPage("", LogFilter{Limit: 2, Order: OrderDesc})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 4", "msg 3")
+
+	page, err = fb.ReadPage("", LogFilter{Limit: 2, Order: OrderDesc, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 2", "msg 1")
+
+	back, err := fb.ReadPage("", LogFilter{Limit: 2, Order: OrderDesc, Cursor: page.PrevCursor})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, back.Entries, "msg 4", "msg 3")
+	if back.PrevCursor != "" {
+		t.Error("Paging back to the first page should not return a previous cursor")
+	}
+}
+
+func TestFileBackendClearLogs(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 5)
+
+	// msg 2 is exactly at the boundary and removed
+	before := time.Date(2025, 1, 1, 12, 0, 2, 0, time.UTC)
+	if err := fb.ClearLogs(before); err != nil {
+		t.Fatalf("ClearLogs failed: %v", err)
+	}
+
+	entries, err := fb.Read("", LogFilter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expectMessages(t, entries, "msg 3", "msg 4")
+}
+
+func TestFileBackendStream(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 5)
+
+	var got []LogEntry
+	for entry, err := range fb.Stream(context.Background(), "", LogFilter{Order: OrderDesc}) {
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		got = append(got, entry)
+		if len(got) == 3 {
+			break
+		}
+	}
+	expectMessages(t, got, "msg 4", "msg 3", "msg 2")
+}
+
+func TestFileBackendStreamCancelled(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	var streamErr error
+	for _, err := range fb.Stream(ctx, "", LogFilter{}) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		count++
+		cancel()
+	}
+
+	if count != 1 {
+		t.Errorf("Expected 1 entry before cancellation, got %d", count)
+	}
+	if !errors.Is(streamErr, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", streamErr)
+	}
+}
+
+func TestFileBackendMetadataFilter(t *testing.T) {
+	fb := newTestBackend(t, &FileBackend{}, FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")}, 0)
+
+	now := time.Now()
+	fb.Write(LogEntry{Level: LevelWarn, Message: "slow", Timestamp: now, Metadata: map[string]interface{}{"component": "scheduler"}})
+	fb.Write(LogEntry{Level: LevelWarn, Message: "slow", Timestamp: now, Metadata: map[string]interface{}{"component": "storage"}})
+	fb.Write(LogEntry{Level: LevelInfo, Message: "fine", Timestamp: now})
+
+	entries, err := fb.Read("", LogFilter{
+		MinLevel: LevelWarn,
+		Metadata: []MetadataPredicate{{Key: "component", Op: OpEq, Value: "scheduler"}},
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Metadata["component"] != "scheduler" {
+		t.Errorf("Expected the scheduler entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,173 @@
+// /logger/backend_memory.go
+
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultMemoryEntries is used when MemoryConfig sets no limit
+const defaultMemoryEntries = 10000
+
+// MemoryBackend keeps the most recent entries in memory, evicting the oldest
+// once MaxEntries or MaxBytes is exceeded. Cursors carry sequence numbers, so
+// paging stays stable while entries are evicted.
+type MemoryBackend struct {
+	mu      sync.RWMutex
+	config  MemoryConfig
+	entries []memoryEntry // oldest first
+	bytes   int
+	nextSeq int64
+}
+
+type memoryEntry struct {
+	LogEntry
+	seq  int64
+	size int
+}
+
+func (mb *MemoryBackend) Init(config interface{}) error {
+	memoryConfig, ok := config.(MemoryConfig)
+	if !ok {
+		return fmt.Errorf("invalid config type for memory backend")
+	}
+	if memoryConfig.MaxEntries < 0 || memoryConfig.MaxBytes < 0 {
+		return fmt.Errorf("invalid memory backend limits: %d entries, %d bytes",
+			memoryConfig.MaxEntries, memoryConfig.MaxBytes)
+	}
+	if memoryConfig.MaxEntries == 0 && memoryConfig.MaxBytes == 0 {
+		memoryConfig.MaxEntries = defaultMemoryEntries
+	}
+
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.config = memoryConfig
+	mb.entries = nil
+	mb.bytes = 0
+	return nil
+}
+
+// entrySize approximates the memory held by an entry
+func entrySize(entry LogEntry) int {
+	size := len(entry.Level) + len(entry.Message) + 64
+	for k, v := range entry.Metadata {
+		size += len(k) + len(fmt.Sprint(v)) + 16
+	}
+	return size
+}
+
+func (mb *MemoryBackend) Write(entry LogEntry) error {
+	// Copy metadata so later changes by the caller do not leak in
+	if entry.Metadata != nil {
+		metadata := make(map[string]interface{}, len(entry.Metadata))
+		for k, v := range entry.Metadata {
+			metadata[k] = v
+		}
+		entry.Metadata = metadata
+	}
+
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	me := memoryEntry{LogEntry: entry, seq: mb.nextSeq, size: entrySize(entry)}
+	mb.nextSeq++
+	mb.entries = append(mb.entries, me)
+	mb.bytes += me.size
+
+	// Evict the oldest entries, always keeping the newest one
+	evict := 0
+	for evict < len(mb.entries)-1 &&
+		((mb.config.MaxEntries > 0 && len(mb.entries)-evict > mb.config.MaxEntries) ||
+			(mb.config.MaxBytes > 0 && mb.bytes > mb.config.MaxBytes)) {
+		mb.bytes -= mb.entries[evict].size
+		mb.entries[evict] = memoryEntry{}
+		evict++
+	}
+	// append reallocates once the front is used up, releasing evicted slots
+	mb.entries = mb.entries[evict:]
+	return nil
+}
+
+func (mb *MemoryBackend) Read(level LogLevel, filter LogFilter) ([]LogEntry, error) {
+	page, err := mb.ReadPage(level, filter)
+	if err != nil {
+		return nil, err
+	}
+	return page.Entries, nil
+}
+
+// ReadPage pages over the retained entries; cursors hold sequence numbers
+func (mb *MemoryBackend) ReadPage(level LogLevel, filter LogFilter) (LogPage, error) {
+	m, err := newEntryMatcher(level, filter)
+	if err != nil {
+		return LogPage{}, err
+	}
+
+	mb.mu.RLock()
+	defer mb.mu.RUnlock()
+
+	entries := mb.entries
+	return readPage(filter, func(from *pageCursor, forward bool, max int, visit func(pageHit) bool) error {
+		// Index of the first entry with seq >= the cursor boundary
+		start := len(entries)
+		if from == nil {
+			if forward {
+				start = 0
+			}
+		} else {
+			start = sort.Search(len(entries), func(i int) bool { return entries[i].seq >= from.ID })
+		}
+
+		hit := func(i int) bool {
+			e := entries[i]
+			if !m.match(e.LogEntry) {
+				return true
+			}
+			return visit(pageHit{
+				entry:  e.LogEntry,
+				before: pageCursor{ID: e.seq},
+				after:  pageCursor{ID: e.seq + 1, Forward: true},
+			})
+		}
+
+		if forward {
+			for i := start; i < len(entries); i++ {
+				if !hit(i) {
+					return nil
+				}
+			}
+			return nil
+		}
+		for i := start - 1; i >= 0; i-- {
+			if !hit(i) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+func (mb *MemoryBackend) ClearLogs(before time.Time) error {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+
+	kept := make([]memoryEntry, 0, len(mb.entries))
+	mb.bytes = 0
+	for _, e := range mb.entries {
+		// Like the file backend, keep only entries after before
+		if !e.Timestamp.After(before) {
+			continue
+		}
+		kept = append(kept, e)
+		mb.bytes += e.size
+	}
+	mb.entries = kept
+	return nil
+}
+
+func (mb *MemoryBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,115 @@
+// /logger/backend_memory_test.go
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryBackendClearLogs(t *testing.T) {
+	mb := newTestBackend(t, &MemoryBackend{}, MemoryConfig{}, 5)
+
+	// msg 2 is exactly at the boundary and removed, as in the file backend
+	if err := mb.ClearLogs(time.Date(2025, 1, 1, 12, 0, 2, 0, time.UTC)); err != nil {
+		t.Fatalf("ClearLogs failed: %v", err)
+	}
+	entries, _ := mb.Read("", LogFilter{})
+	expectMessages(t, entries, "msg 3", "msg 4")
+}
+
+func TestMemoryBackendEviction(t *testing.T) {
+	mb := newTestBackend(t, &MemoryBackend{}, MemoryConfig{MaxEntries: 5}, 12)
+	entries, err := mb.Read("", LogFilter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expectMessages(t, entries, "msg 7", "msg 8", "msg 9", "msg 10", "msg 11")
+
+	mb = newTestBackend(t, &MemoryBackend{}, MemoryConfig{MaxBytes: 3 * entrySize(LogEntry{
+		Level: LevelInfo, Message: "msg 10", Metadata: map[string]interface{}{"n": 10},
+	})}, 12)
+	entries, _ = mb.Read("", LogFilter{})
+	expectMessages(t, entries, "msg 9", "msg 10", "msg 11")
+}
+
+func TestMemoryBackendFilter(t *testing.T) {
+	mb := newTestBackend(t, &MemoryBackend{}, MemoryConfig{}, 10)
+	mb.Write(LogEntry{Level: LevelError, Message: "disk full", Timestamp: time.Now()})
+
+	entries, err := mb.Read(LevelError, LogFilter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expectMessages(t, entries, "disk full")
+
+	filter, err := ParseFilter("meta.n >= 7 and msg ~ msg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter.Order = OrderDesc
+	entries, _ = mb.Read("", filter)
+	expectMessages(t, entries, "msg 9", "msg 8", "msg 7")
+
+	if _, err := mb.Read("", LogFilter{Regex: "("}); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestMemoryBackendPagingAcrossEviction(t *testing.T) {
+	mb := newTestBackend(t, &MemoryBackend{}, MemoryConfig{MaxEntries: 6}, 6)
+
+	page, err := mb.ReadPage("", LogFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 0", "msg 1")
+
+	// New writes evict msg 0..2; the cursor still points after msg 1
+	for i := 6; i < 9; i++ {
+		mb.Write(LogEntry{Level: LevelInfo, Message: fmt.Sprintf("msg %d", i), Timestamp: time.Now()})
+	}
+	page, err = mb.ReadPage("", LogFilter{Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 3", "msg 4")
+
+	// Everything before msg 3 was evicted
+	page, _ = mb.ReadPage("", LogFilter{Limit: 2, Cursor: page.PrevCursor})
+	expectMessages(t, page.Entries)
+}
+
+func TestMemoryBackendManager(t *testing.T) {
+	lm, err := NewLogManager(Config{Backend: BackendMemory, BackendConfig: MemoryConfig{MaxEntries: 100}})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	meta := map[string]interface{}{"user": "alice"}
+	lm.WriteLogWithMetadata(LevelInfo, "login", meta)
+	meta["user"] = "mallory"
+	lm.WriteLog(LevelWarn, "slow")
+
+	entries, err := lm.ReadLogs("", LogFilter{Metadata: []MetadataPredicate{{Key: "user", Op: OpEq, Value: "alice"}}})
+	if err != nil {
+		t.Fatalf("ReadLogs failed: %v", err)
+	}
+	expectMessages(t, entries, "login")
+
+	if err := lm.ClearLogs(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("ClearLogs failed: %v", err)
+	}
+	entries, _ = lm.ReadLogs("", LogFilter{})
+	if len(entries) != 0 {
+		t.Errorf("expected no entries after ClearLogs, got %d", len(entries))
+	}
+
+	if err := (&MemoryBackend{}).Init(MemoryConfig{MaxEntries: -1}); err == nil ||
+		!strings.Contains(err.Error(), "invalid") {
+		t.Errorf("expected error for negative limit, got %v", err)
+	}
+}
@@ -3,13 +3,461 @@
 package logger
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// SQLBackend stores logs in a table through database/sql. The application
+// must register a driver for SQLConfig.Driver, e.g.
+// import _ "github.com/go-sql-driver/mysql".
+//
+// Table layout: id (auto increment), ts (unix nanoseconds), level, message,
+// metadata (JSON). An index on (ts, id) backs keyset pagination.
 type SQLBackend struct {
-	config SQLConfig
-	// db     *sql.DB  // Add when implementing real DB connection
+	config   SQLConfig
+	db       *sql.DB
+	dialect  sqlDialect
+	noRegexp bool // SQLite without a REGEXP function
+}
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// errNoRegexp is returned for regex filters on SQLite without REGEXP
+var errNoRegexp = errors.New("regex filters need a REGEXP function registered with the SQLite driver")
+
+// sqlDialect covers the differences between the supported drivers
+type sqlDialect struct {
+	driver string
+}
+
+func (d sqlDialect) postgres() bool {
+	return d.driver == "postgres" || d.driver == "pgx"
+}
+
+func (d sqlDialect) mysql() bool {
+	return d.driver == "mysql"
+}
+
+// sqlite covers every other driver
+func (d sqlDialect) sqlite() bool {
+	return !d.postgres() && !d.mysql()
+}
+
+// placeholder returns the bind parameter for the n-th (1-based) argument
+func (d sqlDialect) placeholder(n int) string {
+	if d.postgres() {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+func (d sqlDialect) createTable(table string) []string {
+	index := "idx_" + table + "_ts_id"
+	switch {
+	case d.mysql():
+		return []string{fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	ts BIGINT NOT NULL,
+	level VARCHAR(16) NOT NULL,
+	message TEXT NOT NULL,
+	metadata TEXT,
+	INDEX %s (ts, id)
+)`, table, index)}
+	case d.postgres():
+		return []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id BIGSERIAL PRIMARY KEY,
+	ts BIGINT NOT NULL,
+	level VARCHAR(16) NOT NULL,
+	message TEXT NOT NULL,
+	metadata TEXT
+)`, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (ts, id)", index, table),
+		}
+	default:
+		return []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts BIGINT NOT NULL,
+	level VARCHAR(16) NOT NULL,
+	message TEXT NOT NULL,
+	metadata TEXT
+)`, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (ts, id)", index, table),
+		}
+	}
+}
+
+// sqlQuery accumulates WHERE conditions and their bind arguments
+type sqlQuery struct {
+	dialect sqlDialect
+	where   []string
+	args    []interface{}
+}
+
+// arg binds v and returns its placeholder
+func (q *sqlQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return q.dialect.placeholder(len(q.args))
+}
+
+func (q *sqlQuery) whereClause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// filter adds the conditions equivalent to entryMatcher.match
+func (q *sqlQuery) filter(level LogLevel, filter LogFilter) {
+	if level != "" {
+		q.where = append(q.where, "level = "+q.arg(string(level)))
+	}
+	if len(filter.Levels) > 0 || filter.MinLevel != "" {
+		q.where = append(q.where, q.levelIn(allowedLevels(filter)))
+	}
+	if filter.Contains != "" {
+		q.where = append(q.where, q.contains(filter.Contains, filter.IgnoreCase))
+	}
+	for _, term := range filter.Excludes {
+		if term != "" {
+			q.where = append(q.where, "NOT ("+q.contains(term, filter.IgnoreCase)+")")
+		}
+	}
+	if filter.Regex != "" {
+		q.where = append(q.where, q.regex(filter.Regex, filter.IgnoreCase))
+	}
+	if filter.StartTime != nil {
+		q.where = append(q.where, "ts >= "+q.arg(filter.StartTime.UnixNano()))
+	}
+	if filter.EndTime != nil {
+		q.where = append(q.where, "ts <= "+q.arg(filter.EndTime.UnixNano()))
+	}
+	for _, p := range filter.Metadata {
+		q.where = append(q.where, q.metadata(p))
+	}
+	if filter.Expr != nil {
+		q.where = append(q.where, q.expr(filter.Expr))
+	}
+}
+
+// expr compiles a parsed query to a condition with the same semantics as
+// QueryNode.Eval. Metadata comparisons are wrapped in COALESCE so that a
+// missing key is false rather than NULL, which keeps NOT consistent.
+func (q *sqlQuery) expr(node QueryNode) string {
+	switch n := node.(type) {
+	case *AndNode:
+		return "(" + q.expr(n.Left) + " AND " + q.expr(n.Right) + ")"
+	case *OrNode:
+		return "(" + q.expr(n.Left) + " OR " + q.expr(n.Right) + ")"
+	case *NotNode:
+		return "NOT (" + q.expr(n.Operand) + ")"
+	case *CompareNode:
+		return q.compare(n)
+	}
+	return "1 = 0"
+}
+
+// checkExpr reports query nodes that expr cannot compile
+func checkExpr(node QueryNode) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *AndNode:
+		return errors.Join(checkExpr(n.Left), checkExpr(n.Right))
+	case *OrNode:
+		return errors.Join(checkExpr(n.Left), checkExpr(n.Right))
+	case *NotNode:
+		return checkExpr(n.Operand)
+	case *CompareNode:
+		return nil
+	}
+	return fmt.Errorf("query node %T cannot be compiled to SQL", node)
+}
+
+// usesRegex reports whether filter matches a regular expression anywhere
+func usesRegex(filter LogFilter) bool {
+	if filter.Regex != "" {
+		return true
+	}
+	var walk func(node QueryNode) bool
+	walk = func(node QueryNode) bool {
+		switch n := node.(type) {
+		case *AndNode:
+			return walk(n.Left) || walk(n.Right)
+		case *OrNode:
+			return walk(n.Left) || walk(n.Right)
+		case *NotNode:
+			return walk(n.Operand)
+		case *CompareNode:
+			return n.Op == QueryMatch || n.Op == QueryNotMatch
+		}
+		return false
+	}
+	return walk(filter.Expr)
+}
+
+func (q *sqlQuery) compare(n *CompareNode) string {
+	switch n.Field {
+	case FieldLevel:
+		switch n.Op {
+		case QueryEq:
+			return "level = " + q.arg(n.Value)
+		case QueryNe:
+			return "level <> " + q.arg(n.Value)
+		}
+		levels := make(map[LogLevel]bool)
+		for _, l := range Levels() {
+			if sev, _ := l.Severity(); compareResult(n.Op, sev-int(n.num)) {
+				levels[l] = true
+			}
+		}
+		return q.levelIn(levels)
+
+	case FieldMessage:
+		switch n.Op {
+		case QueryMatch:
+			return q.regexOn("message", n.Value, false)
+		case QueryNotMatch:
+			return "NOT (" + q.regexOn("message", n.Value, false) + ")"
+		}
+		column := "message"
+		if q.dialect.mysql() {
+			column = "CAST(message AS BINARY)"
+		}
+		return fmt.Sprintf("%s %s %s", column, sqlOp(n.Op), q.arg(n.Value))
+
+	case FieldTime:
+		return fmt.Sprintf("ts %s %s", n.Op, q.arg(n.t.UnixNano()))
+
+	case FieldMeta:
+		var cond string
+		switch n.Op {
+		case QueryMatch:
+			cond = q.regexOn(q.metadataField(n.Key), n.Value, false)
+		case QueryNotMatch:
+			cond = "NOT (" + q.regexOn(q.metadataField(n.Key), n.Value, false) + ")"
+		default:
+			cond = q.metadata(n.predicate())
+		}
+		return "COALESCE(" + cond + ", FALSE)"
+	}
+	return "1 = 0"
+}
+
+func sqlOp(op QueryOp) string {
+	if op == QueryNe {
+		return "<>"
+	}
+	return string(op)
+}
+
+func (q *sqlQuery) levelIn(levels map[LogLevel]bool) string {
+	if len(levels) == 0 {
+		return "1 = 0"
+	}
+	names := make([]string, 0, len(levels))
+	for l := range levels {
+		names = append(names, string(l))
+	}
+	sort.Strings(names)
+
+	params := make([]string, len(names))
+	for i, name := range names {
+		params[i] = q.arg(name)
+	}
+	return "level IN (" + strings.Join(params, ", ") + ")"
+}
+
+// contains matches a substring of the message. LIKE is case-insensitive by
+// default in MySQL and SQLite, so case-sensitive matches are forced per driver.
+func (q *sqlQuery) contains(term string, ignoreCase bool) string {
+	if ignoreCase {
+		return "LOWER(message) LIKE " + q.arg("%"+escapeLike(strings.ToLower(term))+"%") + " ESCAPE '!'"
+	}
+	switch {
+	case q.dialect.postgres():
+		return "message LIKE " + q.arg("%"+escapeLike(term)+"%") + " ESCAPE '!'"
+	case q.dialect.mysql():
+		return "CAST(message AS BINARY) LIKE " + q.arg("%"+escapeLike(term)+"%") + " ESCAPE '!'"
+	default:
+		return "instr(message, " + q.arg(term) + ") > 0"
+	}
+}
+
+// regex matches the message with the driver's regular expression operator.
+// SQLite needs a REGEXP function registered by the driver; checkFilter
+// rejects regex filters when Init found none.
+func (q *sqlQuery) regex(pattern string, ignoreCase bool) string {
+	return q.regexOn("message", pattern, ignoreCase)
+}
+
+func (q *sqlQuery) regexOn(column, pattern string, ignoreCase bool) string {
+	switch {
+	case q.dialect.postgres():
+		if ignoreCase {
+			return column + " ~* " + q.arg(pattern)
+		}
+		return column + " ~ " + q.arg(pattern)
+	case q.dialect.mysql():
+		flags := "'c'"
+		if ignoreCase {
+			flags = "'i'"
+		}
+		return "REGEXP_LIKE(" + column + ", " + q.arg(pattern) + ", " + flags + ")"
+	default:
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		return column + " REGEXP " + q.arg(pattern)
+	}
+}
+
+func jsonPath(key string) string {
+	return `$."` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+}
+
+// metadataField extracts the metadata value stored under key as text, the
+// way fmt.Sprint prints it in memory. SQLite returns booleans as 1 and 0, so
+// they are mapped back to 'true' and 'false'.
+func (q *sqlQuery) metadataField(key string) string {
+	switch {
+	case q.dialect.postgres():
+		return "(metadata::jsonb ->> " + q.arg(key) + ")"
+	case q.dialect.mysql():
+		return "JSON_UNQUOTE(JSON_EXTRACT(metadata, " + q.arg(jsonPath(key)) + "))"
+	default:
+		return "(CASE json_type(metadata, " + q.arg(jsonPath(key)) + ") WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' " +
+			"ELSE CAST(json_extract(metadata, " + q.arg(jsonPath(key)) + ") AS TEXT) END)"
+	}
+}
+
+// metadataNumber extracts the metadata value stored under key if it is a
+// JSON number and NULL otherwise, so comparing strings or booleans with a
+// number is false as in matchMetadata
+func (q *sqlQuery) metadataNumber(key string) string {
+	switch {
+	case q.dialect.postgres():
+		return "(CASE WHEN jsonb_typeof(metadata::jsonb -> " + q.arg(key) + ") = 'number' " +
+			"THEN (metadata::jsonb ->> " + q.arg(key) + ")::DOUBLE PRECISION END)"
+	case q.dialect.mysql():
+		return "(CASE WHEN JSON_TYPE(JSON_EXTRACT(metadata, " + q.arg(jsonPath(key)) + ")) IN ('INTEGER', 'UNSIGNED INTEGER', 'DOUBLE', 'DECIMAL') " +
+			"THEN CAST(JSON_EXTRACT(metadata, " + q.arg(jsonPath(key)) + ") AS DOUBLE) END)"
+	default:
+		return "(CASE WHEN json_type(metadata, " + q.arg(jsonPath(key)) + ") IN ('integer', 'real') " +
+			"THEN json_extract(metadata, " + q.arg(jsonPath(key)) + ") END)"
+	}
+}
+
+// metadata compares a JSON field of the metadata column
+func (q *sqlQuery) metadata(p MetadataPredicate) string {
+	if f, ok := numericValue(p.Value, false); ok {
+		field := q.metadataNumber(p.Key)
+		return fmt.Sprintf("%s %s %s", field, p.Op, q.arg(f))
+	}
+	field := q.metadataField(p.Key)
+	return fmt.Sprintf("%s %s %s", field, p.Op, q.arg(metadataText(p.Value)))
+}
+
+// keyset adds the condition selecting rows past the cursor boundary
+func (q *sqlQuery) keyset(from *pageCursor, forward bool) {
+	if from == nil {
+		return
+	}
+	op := "<"
+	if forward {
+		op = ">"
+	}
+	q.where = append(q.where, fmt.Sprintf("(ts %s %s OR (ts = %s AND id %s %s))",
+		op, q.arg(from.Time), q.arg(from.Time), op, q.arg(from.ID)))
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return r.Replace(s)
+}
+
+// checkFilter validates filter the same way the file backend does
+func (sb *SQLBackend) checkFilter(level LogLevel, filter LogFilter) error {
+	if _, err := newEntryMatcher(level, filter); err != nil {
+		return err
+	}
+	if sb.noRegexp && usesRegex(filter) {
+		return errNoRegexp
+	}
+	return checkExpr(filter.Expr)
+}
+
+// buildSelect builds the page query for ReadPage
+func (sb *SQLBackend) buildSelect(level LogLevel, filter LogFilter, from *pageCursor, forward bool, max int) (string, []interface{}) {
+	q := &sqlQuery{dialect: sb.dialect}
+	q.filter(level, filter)
+	q.keyset(from, forward)
+
+	dir := "DESC"
+	if forward {
+		dir = "ASC"
+	}
+
+	query := fmt.Sprintf("SELECT id, ts, level, message, metadata FROM %s%s ORDER BY ts %s, id %s",
+		sb.config.TableName, q.whereClause(), dir, dir)
+	if max > 0 {
+		query += " LIMIT " + strconv.Itoa(max)
+	}
+	return query, q.args
+}
+
+// buildAggregate builds the GROUP BY query for Aggregate. Selected columns
+// are the grouped expressions in the order level, metadata, bucket, then the count.
+func (sb *SQLBackend) buildAggregate(level LogLevel, filter LogFilter, opts AggregateOptions) (string, []interface{}) {
+	q := &sqlQuery{dialect: sb.dialect}
+
+	var columns []string
+	if opts.ByLevel {
+		columns = append(columns, "level")
+	}
+	if opts.ByMetadata != "" {
+		columns = append(columns, "COALESCE("+q.metadataField(opts.ByMetadata)+", '')")
+	}
+	if opts.Bucket > 0 {
+		bucket := strconv.FormatInt(int64(opts.Bucket), 10)
+		if sb.dialect.mysql() {
+			columns = append(columns, "(ts DIV "+bucket+") * "+bucket)
+		} else {
+			columns = append(columns, "(ts / "+bucket+") * "+bucket)
+		}
+	}
+
+	q.filter(level, filter)
+
+	query := "SELECT " + strings.Join(append(columns, "COUNT(*)"), ", ") +
+		" FROM " + sb.config.TableName + q.whereClause()
+	if len(columns) > 0 {
+		positions := make([]string, len(columns))
+		for i := range columns {
+			positions[i] = strconv.Itoa(i + 1)
+		}
+		query += " GROUP BY " + strings.Join(positions, ", ")
+	}
+	return query, q.args
+}
+
+// buildTopMessages builds the query for AggregateOptions.TopMessages
+func (sb *SQLBackend) buildTopMessages(level LogLevel, filter LogFilter, n int) (string, []interface{}) {
+	q := &sqlQuery{dialect: sb.dialect}
+	q.filter(level, filter)
+	query := fmt.Sprintf("SELECT message, COUNT(*) AS cnt FROM %s%s GROUP BY message ORDER BY cnt DESC, message ASC LIMIT %d",
+		sb.config.TableName, q.whereClause(), n)
+	return query, q.args
 }
 
 func (sb *SQLBackend) Init(config interface{}) error {
@@ -18,39 +466,288 @@ func (sb *SQLBackend) Init(config interface{}) error {
 		return fmt.Errorf("invalid config type for SQL backend")
 	}
 
+	if sqlConfig.TableName == "" {
+		sqlConfig.TableName = "logs"
+	}
+	if !tableNamePattern.MatchString(sqlConfig.TableName) {
+		return fmt.Errorf("invalid table name: %q", sqlConfig.TableName)
+	}
+
 	sb.config = sqlConfig
+	sb.dialect = sqlDialect{driver: sqlConfig.Driver}
 
-	// TODO: Initialize database connection
-	// db, err := sql.Open(sqlConfig.Driver, sqlConfig.DSN)
-	// if err != nil {
-	//     return fmt.Errorf("failed to connect to database: %w", err)
-	// }
-	// sb.db = db
+	db, err := sql.Open(sqlConfig.Driver, sqlConfig.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
 
-	// TODO: Create table if not exists
+	// Create table if not exists
+	for _, stmt := range sb.dialect.createTable(sqlConfig.TableName) {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return fmt.Errorf("failed to create log table: %w", err)
+		}
+	}
+
+	if sb.dialect.sqlite() {
+		// SQLite has no REGEXP implementation of its own; drivers may add one
+		var matched bool
+		sb.noRegexp = db.QueryRow("SELECT 'a' REGEXP 'a'").Scan(&matched) != nil
+	}
 
+	sb.db = db
 	return nil
 }
 
 func (sb *SQLBackend) Write(entry LogEntry) error {
-	// TODO: INSERT INTO logs (level, message, timestamp) VALUES (?, ?, ?)
+	if sb.db == nil {
+		return fmt.Errorf("SQL backend not initialized")
+	}
+
+	var metadata sql.NullString
+	if len(entry.Metadata) > 0 {
+		data, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		metadata = sql.NullString{String: string(data), Valid: true}
+	}
+
+	q := &sqlQuery{dialect: sb.dialect}
+	query := fmt.Sprintf("INSERT INTO %s (ts, level, message, metadata) VALUES (%s, %s, %s, %s)",
+		sb.config.TableName,
+		q.arg(entry.Timestamp.UnixNano()), q.arg(string(entry.Level)), q.arg(entry.Message), q.arg(metadata))
+
+	if _, err := sb.db.Exec(query, q.args...); err != nil {
+		return fmt.Errorf("failed to write log: %w", err)
+	}
 	return nil
 }
 
 func (sb *SQLBackend) Read(level LogLevel, filter LogFilter) ([]LogEntry, error) {
-	// TODO: SELECT * FROM logs WHERE level = ? AND timestamp BETWEEN ? AND ?
-	return []LogEntry{}, nil
+	page, err := sb.ReadPage(level, filter)
+	if err != nil {
+		return nil, err
+	}
+	return page.Entries, nil
+}
+
+// ReadPage reads one page of logs using keyset pagination on (ts, id)
+func (sb *SQLBackend) ReadPage(level LogLevel, filter LogFilter) (LogPage, error) {
+	if sb.db == nil {
+		return LogPage{}, fmt.Errorf("SQL backend not initialized")
+	}
+
+	if err := sb.checkFilter(level, filter); err != nil {
+		return LogPage{}, err
+	}
+
+	return readPage(filter, func(from *pageCursor, forward bool, max int, visit func(pageHit) bool) error {
+		query, args := sb.buildSelect(level, filter, from, forward, max)
+		rows, err := sb.db.Query(query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			id, entry, err := scanLogRow(rows)
+			if err != nil {
+				return err
+			}
+			key := entry.Timestamp.UnixNano()
+			if !visit(pageHit{
+				entry:  entry,
+				before: pageCursor{Time: key, ID: id},
+				after:  pageCursor{Time: key, ID: id, Forward: true},
+			}) {
+				return nil
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// Stream reads matching rows one at a time, honoring Order, Cursor and Limit.
+// The rows are closed as soon as iteration stops or ctx is cancelled.
+func (sb *SQLBackend) Stream(ctx context.Context, level LogLevel, filter LogFilter) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		if sb.db == nil {
+			yield(LogEntry{}, fmt.Errorf("SQL backend not initialized"))
+			return
+		}
+
+		if err := sb.checkFilter(level, filter); err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+
+		from, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+
+		forward := filter.Order != OrderDesc
+		if from != nil {
+			forward = from.Forward
+		}
+
+		query, args := sb.buildSelect(level, filter, from, forward, filter.Limit)
+		rows, err := sb.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(LogEntry{}, fmt.Errorf("failed to read logs: %w", err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			_, entry, err := scanLogRow(rows)
+			if err != nil {
+				yield(LogEntry{}, err)
+				return
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(LogEntry{}, err)
+		}
+	}
+}
+
+// Aggregate pushes grouping and counting down to GROUP BY queries
+func (sb *SQLBackend) Aggregate(ctx context.Context, level LogLevel, filter LogFilter, opts AggregateOptions) (AggregateResult, error) {
+	if sb.db == nil {
+		return AggregateResult{}, fmt.Errorf("SQL backend not initialized")
+	}
+	if err := sb.checkFilter(level, filter); err != nil {
+		return AggregateResult{}, err
+	}
+
+	query, args := sb.buildAggregate(level, filter, opts)
+	rows, err := sb.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return AggregateResult{}, fmt.Errorf("failed to aggregate logs: %w", err)
+	}
+	defer rows.Close()
+
+	var result AggregateResult
+	for rows.Next() {
+		var (
+			group  AggregateGroup
+			lvl    string
+			bucket int64
+			dest   []interface{}
+		)
+		if opts.ByLevel {
+			dest = append(dest, &lvl)
+		}
+		if opts.ByMetadata != "" {
+			dest = append(dest, &group.MetaValue)
+		}
+		if opts.Bucket > 0 {
+			dest = append(dest, &bucket)
+		}
+		dest = append(dest, &group.Count)
+
+		if err := rows.Scan(dest...); err != nil {
+			return AggregateResult{}, fmt.Errorf("failed to scan aggregate row: %w", err)
+		}
+		group.Level = LogLevel(lvl)
+		if opts.Bucket > 0 {
+			group.BucketStart = time.Unix(0, bucket)
+		}
+		if group.Count == 0 {
+			continue
+		}
+		result.Total += group.Count
+		result.Groups = append(result.Groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return AggregateResult{}, err
+	}
+	sortGroups(result.Groups)
+
+	if opts.TopMessages > 0 {
+		query, args := sb.buildTopMessages(level, filter, opts.TopMessages)
+		rows, err := sb.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return AggregateResult{}, fmt.Errorf("failed to aggregate messages: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var mc MessageCount
+			if err := rows.Scan(&mc.Message, &mc.Count); err != nil {
+				return AggregateResult{}, fmt.Errorf("failed to scan aggregate row: %w", err)
+			}
+			result.TopMessages = append(result.TopMessages, mc)
+		}
+		if err := rows.Err(); err != nil {
+			return AggregateResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// scanLogRow scans a row selected as: id, ts, level, message, metadata
+func scanLogRow(rows *sql.Rows) (int64, LogEntry, error) {
+	var (
+		id       int64
+		ts       int64
+		level    string
+		message  string
+		metadata sql.NullString
+	)
+	if err := rows.Scan(&id, &ts, &level, &message, &metadata); err != nil {
+		return 0, LogEntry{}, fmt.Errorf("failed to scan log row: %w", err)
+	}
+
+	entry := LogEntry{
+		Level:     LogLevel(level),
+		Message:   message,
+		Timestamp: time.Unix(0, ts),
+	}
+	if metadata.Valid && metadata.String != "" {
+		if err := json.Unmarshal([]byte(metadata.String), &entry.Metadata); err != nil {
+			return 0, LogEntry{}, fmt.Errorf("failed to decode metadata: %w", err)
+		}
+	}
+	return id, entry, nil
 }
 
 func (sb *SQLBackend) ClearLogs(before time.Time) error {
-	// TODO: DELETE FROM logs WHERE timestamp < ?
+	if sb.db == nil {
+		return fmt.Errorf("SQL backend not initialized")
+	}
+
+	q := &sqlQuery{dialect: sb.dialect}
+	query := fmt.Sprintf("DELETE FROM %s WHERE ts <= %s", sb.config.TableName, q.arg(before.UnixNano()))
+	if _, err := sb.db.Exec(query, q.args...); err != nil {
+		return fmt.Errorf("failed to clear logs: %w", err)
+	}
+	return nil
+}
+
+// Ping checks that the database is reachable
+func (sb *SQLBackend) Ping(ctx context.Context) error {
+	if sb.db == nil {
+		return fmt.Errorf("database is not open")
+	}
+	if err := sb.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
 	return nil
 }
 
 func (sb *SQLBackend) Close() error {
-	// TODO: Close database connection
-	// if sb.db != nil {
-	//     return sb.db.Close()
-	// }
+	if sb.db != nil {
+		err := sb.db.Close()
+		sb.db = nil
+		return err
+	}
 	return nil
 }
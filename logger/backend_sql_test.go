@@ -0,0 +1,298 @@
+// /logger/backend_sql_test.go
+
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+func init() {
+	// SQLite has no REGEXP implementation of its own. Like an application
+	// using regex filters, the tests register one with the "sqlite" driver;
+	// "sqlite-plain" is the same driver without it.
+	sql.Register("sqlite-plain", &sqlite.Driver{})
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		pattern, ok1 := args[0].(string)
+		value, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, nil
+		}
+		return regexp.MatchString(pattern, value)
+	})
+}
+
+func TestSQLBuildSelectKeyset(t *testing.T) {
+	sb := &SQLBackend{config: SQLConfig{TableName: "logs"}, dialect: sqlDialect{driver: "postgres"}}
+
+	query, args := sb.buildSelect(LevelError, LogFilter{Contains: "disk"}, &pageCursor{Time: 100, ID: 7}, false, 51)
+
+	want := "SELECT id, ts, level, message, metadata FROM logs WHERE level = $1 AND message LIKE $2 ESCAPE '!' AND " +
+		"(ts < $3 OR (ts = $4 AND id < $5)) ORDER BY ts DESC, id DESC LIMIT 51"
+	if query != want {
+		t.Errorf("Unexpected query:\n got: %s\nwant: %s", query, want)
+	}
+	if fmt.Sprint(args) != "[ERROR %disk% 100 100 7]" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+func TestSQLBuildSelectMySQL(t *testing.T) {
+	sb := &SQLBackend{config: SQLConfig{TableName: "logs"}, dialect: sqlDialect{driver: "mysql"}}
+
+	query, args := sb.buildSelect("", LogFilter{Contains: "50%"}, nil, true, 0)
+
+	want := "SELECT id, ts, level, message, metadata FROM logs WHERE CAST(message AS BINARY) LIKE ? ESCAPE '!' ORDER BY ts ASC, id ASC"
+	if query != want {
+		t.Errorf("Unexpected query:\n got: %s\nwant: %s", query, want)
+	}
+	if fmt.Sprint(args) != "[%50!%%]" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+func TestSQLInitRejectsInvalidTableName(t *testing.T) {
+	sb := &SQLBackend{}
+	err := sb.Init(SQLConfig{TableName: "logs; DROP TABLE users", Driver: "mysql"})
+	if err == nil {
+		t.Fatal("Expected error for invalid table name")
+	}
+}
+
+func TestSQLBackendPaging(t *testing.T) {
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")}, 5)
+
+	// Entries sharing a timestamp are ordered by id
+	ts := time.Date(2025, 1, 1, 12, 0, 2, 0, time.UTC)
+	sb.Write(LogEntry{Level: LevelWarn, Message: "msg 2b", Timestamp: ts})
+
+	page, err := sb.ReadPage("", LogFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("ReadPage failed: %v", err)
+	}
+	expectMessages(t, page.Entries, "msg 0", "msg 1")
+	if page.PrevCursor != "" {
+		t.Error("First page should not have a previous cursor")
+	}
+
+	page, _ = sb.ReadPage("", LogFilter{Limit: 2, Cursor: page.NextCursor})
+	expectMessages(t, page.Entries, "msg 2", "msg 2b")
+
+	next, _ := sb.ReadPage("", LogFilter{Limit: 2, Cursor: page.NextCursor})
+	expectMessages(t, next.Entries, "msg 3", "msg 4")
+	if next.NextCursor != "" {
+		t.Error("Last page should not have a next cursor")
+	}
+
+	prev, _ := sb.ReadPage("", LogFilter{Limit: 2, Cursor: page.PrevCursor})
+	expectMessages(t, prev.Entries, "msg 0", "msg 1")
+
+	desc, _ := sb.ReadPage("", LogFilter{Limit: 3, Order: OrderDesc})
+	expectMessages(t, desc.Entries, "msg 4", "msg 3", "msg 2b")
+	desc, _ = sb.ReadPage("", LogFilter{Limit: 3, Order: OrderDesc, Cursor: desc.NextCursor})
+	expectMessages(t, desc.Entries, "msg 2", "msg 1", "msg 0")
+}
+
+func TestSQLBackendFilter(t *testing.T) {
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")}, 6)
+	sb.Write(LogEntry{Level: LevelError, Message: "disk full", Timestamp: time.Now(),
+		Metadata: map[string]interface{}{"node": "gpu-3"}})
+
+	for _, tc := range []struct {
+		filter LogFilter
+		want   []string
+	}{
+		{LogFilter{MinLevel: LevelWarn}, []string{"disk full"}},
+		{LogFilter{Contains: "DISK", IgnoreCase: true}, []string{"disk full"}},
+		{LogFilter{Regex: `^msg [45]$`}, []string{"msg 4", "msg 5"}},
+		{LogFilter{Excludes: []string{"msg"}}, []string{"disk full"}},
+		{LogFilter{Metadata: []MetadataPredicate{{Key: "n", Op: OpGt, Value: 3}}}, []string{"msg 4", "msg 5"}},
+	} {
+		entries, err := sb.Read("", tc.filter)
+		if err != nil {
+			t.Fatalf("Read(%+v) failed: %v", tc.filter, err)
+		}
+		expectMessages(t, entries, tc.want...)
+	}
+
+	filter, err := ParseFilter(`level>=ERROR or (meta.n<2 and not msg~"0")`)
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	entries, err := sb.Read("", filter)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expectMessages(t, entries, "msg 1", "disk full")
+
+	if _, err := sb.Read("", LogFilter{MinLevel: "WARNING"}); err == nil {
+		t.Error("expected error for unknown minimum level")
+	}
+}
+
+// TestSQLMetadataMatchesMemory runs the same predicates through SQLite and
+// the in-memory matcher over values of mixed JSON types
+func TestSQLMetadataMatchesMemory(t *testing.T) {
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")}, 0)
+	mb := &MemoryBackend{}
+	mb.Init(MemoryConfig{})
+
+	// 1e6 as a float64 is what the file backend reads back for 1000000
+	values := []interface{}{"abc", 0, 1.5, 42, "42", true, false, nil, map[string]interface{}{"x": 1}, 1e6}
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, v := range values {
+		entry := LogEntry{Level: LevelInfo, Message: fmt.Sprintf("%d: %v", i, v), Timestamp: base.Add(time.Duration(i) * time.Second),
+			Metadata: map[string]interface{}{"v": v}}
+		sb.Write(entry)
+		mb.Write(entry)
+	}
+	mb.Write(LogEntry{Level: LevelInfo, Message: "missing", Timestamp: base.Add(time.Minute)})
+	sb.Write(LogEntry{Level: LevelInfo, Message: "missing", Timestamp: base.Add(time.Minute)})
+
+	for _, p := range []MetadataPredicate{
+		{Key: "v", Op: OpEq, Value: 0},
+		{Key: "v", Op: OpNe, Value: 0},
+		{Key: "v", Op: OpGe, Value: 1},
+		{Key: "v", Op: OpLt, Value: 100},
+		{Key: "v", Op: OpEq, Value: "abc"},
+		{Key: "v", Op: OpNe, Value: "abc"},
+		{Key: "v", Op: OpEq, Value: "42"},
+		{Key: "v", Op: OpEq, Value: "1000000"},
+		{Key: "v", Op: OpEq, Value: "1.5"},
+		{Key: "v", Op: OpEq, Value: "true"},
+		{Key: "v", Op: OpEq, Value: true},
+		{Key: "v", Op: OpEq, Value: "false"},
+		{Key: "v", Op: OpGt, Value: "a"},
+	} {
+		filter := LogFilter{Metadata: []MetadataPredicate{p}}
+		want, err := mb.Read("", filter)
+		if err != nil {
+			t.Fatalf("memory Read(%v) failed: %v", p, err)
+		}
+		got, err := sb.Read("", filter)
+		if err != nil {
+			t.Fatalf("SQL Read(%v) failed: %v", p, err)
+		}
+		if fmt.Sprint(messages(got)) != fmt.Sprint(messages(want)) {
+			t.Errorf("%s %s %v: SQL = %q, memory = %q", p.Key, p.Op, p.Value, messages(got), messages(want))
+		}
+	}
+}
+
+func TestSQLiteWithoutRegexp(t *testing.T) {
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite-plain", DSN: filepath.Join(t.TempDir(), "logs.db")}, 3)
+
+	filter, err := ParseFilter(`meta.n=1 or msg~"2"`)
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	for _, f := range []LogFilter{{Regex: "^msg"}, filter} {
+		if _, err := sb.Read("", f); !errors.Is(err, errNoRegexp) {
+			t.Errorf("Read(%+v) error = %v, want %v", f, err, errNoRegexp)
+		}
+	}
+
+	entries, err := sb.Read("", LogFilter{Contains: "msg 1"})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expectMessages(t, entries, "msg 1")
+}
+
+func TestSQLBackendStreamAndAggregate(t *testing.T) {
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")}, 4)
+	sb.Write(LogEntry{Level: LevelError, Message: "msg 1", Timestamp: time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC)})
+
+	var streamed []LogEntry
+	for entry, err := range sb.Stream(context.Background(), "", LogFilter{Order: OrderDesc, Limit: 2}) {
+		if err != nil {
+			t.Fatalf("Stream failed: %v", err)
+		}
+		streamed = append(streamed, entry)
+	}
+	expectMessages(t, streamed, "msg 1", "msg 3")
+	if streamed[1].Metadata["n"] != 3.0 {
+		t.Errorf("metadata = %v", streamed[1].Metadata)
+	}
+
+	result, err := sb.Aggregate(context.Background(), "", LogFilter{}, AggregateOptions{ByLevel: true, Bucket: time.Minute, TopMessages: 1})
+	if err != nil {
+		t.Fatalf("Aggregate failed: %v", err)
+	}
+	if result.Total != 5 || len(result.Groups) != 2 || result.Groups[0].Count != 4 || result.Groups[1].Level != LevelError {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+	if len(result.TopMessages) != 1 || result.TopMessages[0] != (MessageCount{Message: "msg 1", Count: 2}) {
+		t.Errorf("top messages = %+v", result.TopMessages)
+	}
+}
+
+func TestSQLBackendClearLogs(t *testing.T) {
+	sb := newTestBackend(t, &SQLBackend{}, SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")}, 4)
+
+	if err := sb.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	// The entry at the boundary is removed too, like in the other backends
+	if err := sb.ClearLogs(time.Date(2025, 1, 1, 12, 0, 2, 0, time.UTC)); err != nil {
+		t.Fatalf("ClearLogs failed: %v", err)
+	}
+	entries, _ := sb.Read("", LogFilter{})
+	expectMessages(t, entries, "msg 3")
+}
+
+func TestSQLBackendManager(t *testing.T) {
+	lm, err := NewLogManager(Config{
+		Backend:       BackendSQL,
+		BackendConfig: SQLConfig{Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "logs.db")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	lm.WriteLogWithMetadata(LevelInfo, "login", map[string]interface{}{"user": "alice"})
+	entries, err := lm.ReadLogs("", LogFilter{Metadata: []MetadataPredicate{{Key: "user", Op: OpEq, Value: "alice"}}})
+	if err != nil {
+		t.Fatalf("ReadLogs failed: %v", err)
+	}
+	expectMessages(t, entries, "login")
+
+	_, err = NewLogManager(Config{Backend: BackendSQL, BackendConfig: SQLConfig{Driver: "nodriver"}})
+	if err == nil {
+		t.Error("expected error for a driver that is not registered")
+	}
+}
+
+// TestSQLPostgresPlaceholders checks that $n parameters are numbered in the
+// order their arguments were bound
+func TestSQLPostgresPlaceholders(t *testing.T) {
+	sb := &SQLBackend{config: SQLConfig{TableName: "logs"}, dialect: sqlDialect{driver: "postgres"}}
+	filter, err := ParseFilter(`level>=WARN and (msg~"disk" or meta.node="gpu-3")`)
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	filter.Contains = "full"
+	filter.Metadata = []MetadataPredicate{{Key: "n", Op: OpGe, Value: 2}}
+
+	query, args := sb.buildSelect(LevelError, filter, &pageCursor{Time: 1, ID: 2}, true, 10)
+	params := regexp.MustCompile(`\$(\d+)`).FindAllStringSubmatch(query, -1)
+	if len(params) != len(args) {
+		t.Fatalf("%d placeholders for %d args in %s", len(params), len(args), query)
+	}
+	for i, p := range params {
+		if p[1] != strconv.Itoa(i+1) {
+			t.Fatalf("placeholder %d is $%s in %s", i+1, p[1], query)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// /logger/backend_syslog.go
+
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotSupported is returned by backends for operations they cannot perform
+var ErrNotSupported = errors.New("operation not supported by backend")
+
+// Syslog facilities (RFC 5424 section 6.2.1)
+const (
+	FacilityKern   = 0
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityAuth   = 4
+	FacilityLocal0 = 16
+	FacilityLocal7 = 23
+)
+
+// syslogTimestamp is RFC 3339 with microseconds, as allowed by RFC 5424
+const syslogTimestamp = "2006-01-02T15:04:05.000000Z07:00"
+
+// SyslogBackend sends entries to a syslog collector as RFC 5424 messages.
+// It is write only: Read and ClearLogs return ErrNotSupported.
+type SyslogBackend struct {
+	mu     sync.Mutex
+	config SyslogConfig
+	conn   net.Conn
+}
+
+func (sb *SyslogBackend) Init(config interface{}) error {
+	syslogConfig, ok := config.(SyslogConfig)
+	if !ok {
+		return fmt.Errorf("invalid config type for syslog backend")
+	}
+
+	switch syslogConfig.Network {
+	case "udp", "tcp", "unixgram":
+	default:
+		return fmt.Errorf("unsupported syslog network: %q", syslogConfig.Network)
+	}
+	if syslogConfig.Address == "" {
+		return errors.New("syslog address is required")
+	}
+	if syslogConfig.Facility < 0 || syslogConfig.Facility > 23 {
+		return fmt.Errorf("invalid syslog facility: %d", syslogConfig.Facility)
+	}
+	if syslogConfig.Hostname == "" {
+		syslogConfig.Hostname, _ = os.Hostname()
+	}
+	if syslogConfig.AppName == "" {
+		syslogConfig.AppName = filepath.Base(os.Args[0])
+	}
+	if syslogConfig.ProcID == "" {
+		syslogConfig.ProcID = strconv.Itoa(os.Getpid())
+	}
+	if syslogConfig.StructuredDataID == "" {
+		syslogConfig.StructuredDataID = "meta@32473"
+	}
+	if syslogConfig.Timeout <= 0 {
+		syslogConfig.Timeout = 5 * time.Second
+	}
+	sb.config = syslogConfig
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.connect()
+}
+
+// connect must be called with sb.mu held
+func (sb *SyslogBackend) connect() error {
+	if sb.conn != nil {
+		sb.conn.Close()
+		sb.conn = nil
+	}
+	conn, err := net.DialTimeout(sb.config.Network, sb.config.Address, sb.config.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	sb.conn = conn
+	return nil
+}
+
+func (sb *SyslogBackend) Write(entry LogEntry) error {
+	msg := sb.format(entry)
+	if sb.config.Network == "tcp" {
+		// Octet-counting framing (RFC 6587 section 3.4.1)
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	// Reconnect once when the connection was lost
+	err := sb.send(msg)
+	if err != nil {
+		if cerr := sb.connect(); cerr != nil {
+			return fmt.Errorf("failed to write log: %w", errors.Join(err, cerr))
+		}
+		err = sb.send(msg)
+	}
+	if err != nil {
+		sb.conn.Close()
+		sb.conn = nil
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+	return nil
+}
+
+// send must be called with sb.mu held
+func (sb *SyslogBackend) send(msg string) error {
+	if sb.conn == nil {
+		return errors.New("syslog not connected")
+	}
+	sb.conn.SetWriteDeadline(time.Now().Add(sb.config.Timeout))
+	_, err := sb.conn.Write([]byte(msg))
+	return err
+}
+
+// format renders entry as an RFC 5424 message without framing
+func (sb *SyslogBackend) format(entry LogEntry) string {
+	var b strings.Builder
+
+	pri := sb.config.Facility*8 + SyslogSeverity(entry.Level)
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		pri,
+		entry.Timestamp.Format(syslogTimestamp),
+		syslogHeaderField(sb.config.Hostname, 255),
+		syslogHeaderField(sb.config.AppName, 48),
+		syslogHeaderField(sb.config.ProcID, 128),
+		syslogHeaderField(sb.config.MsgID, 32))
+
+	writeStructuredData(&b, sb.config.StructuredDataID, entry.Metadata)
+
+	if entry.Message != "" {
+		b.WriteByte(' ')
+		b.WriteString(entry.Message)
+	}
+	return b.String()
+}
+
+// syslogHeaderField keeps printable ASCII without spaces; empty becomes "-"
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sdName keeps the characters allowed in an SD-NAME (max 32)
+func sdName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	return s
+}
+
+var sdValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// writeStructuredData renders metadata as one SD-ELEMENT, or "-" when empty
+func writeStructuredData(b *strings.Builder, id string, metadata map[string]interface{}) {
+	if len(metadata) == 0 {
+		b.WriteByte('-')
+		return
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("[" + id)
+	for _, k := range keys {
+		var value string
+		switch v := metadata[k].(type) {
+		case string:
+			value = v
+		case nil:
+			value = ""
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				value = fmt.Sprint(v)
+			} else {
+				value = string(data)
+			}
+		}
+		fmt.Fprintf(b, ` %s="%s"`, sdName(k), sdValueEscaper.Replace(value))
+	}
+	b.WriteByte(']')
+}
+
+func (sb *SyslogBackend) Read(level LogLevel, filter LogFilter) ([]LogEntry, error) {
+	return nil, fmt.Errorf("syslog backend cannot read logs: %w", ErrNotSupported)
+}
+
+func (sb *SyslogBackend) ClearLogs(before time.Time) error {
+	return fmt.Errorf("syslog backend cannot clear logs: %w", ErrNotSupported)
+}
+
+// Ping reconnects when the connection was lost
+func (sb *SyslogBackend) Ping(ctx context.Context) error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	if sb.conn != nil {
+		return nil
+	}
+	return sb.connect()
+}
+
+func (sb *SyslogBackend) Close() error {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	if sb.conn != nil {
+		err := sb.conn.Close()
+		sb.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// /logger/backend_syslog_test.go
+
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSyslogConfig(network, address string) SyslogConfig {
+	return SyslogConfig{
+		Network:  network,
+		Address:  address,
+		Facility: FacilityLocal0,
+		Hostname: "host1",
+		AppName:  "my app",
+		ProcID:   "42",
+		Timeout:  time.Second,
+	}
+}
+
+func TestSyslogFormat(t *testing.T) {
+	sb := &SyslogBackend{config: testSyslogConfig("udp", "")}
+	sb.config.StructuredDataID = "meta@32473"
+
+	ts := time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC)
+	got := sb.format(LogEntry{
+		Level:     LevelError,
+		Message:   "disk full",
+		Timestamp: ts,
+		Metadata:  map[string]interface{}{"path": `/var/"data"]`, "used": 97.5, "bad key": true},
+	})
+	want := `<131>1 2024-03-01T12:30:45.123456Z host1 myapp 42 - [meta@32473 bad_key="true" path="/var/\"data\"\]" used="97.5"] disk full`
+	if got != want {
+		t.Errorf("format =\n%s\nwant\n%s", got, want)
+	}
+
+	got = sb.format(LogEntry{Level: LevelDebug, Message: "x", Timestamp: ts})
+	if !strings.HasPrefix(got, "<135>1 ") || !strings.HasSuffix(got, " 42 - - x") {
+		t.Errorf("format without metadata = %s", got)
+	}
+}
+
+func TestSyslogUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer pc.Close()
+
+	lm, err := NewLogManager(Config{Backend: BackendSyslog, BackendConfig: testSyslogConfig("udp", pc.LocalAddr().String())})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	if err := lm.WriteLog(LevelWarn, "hello syslog"); err != nil {
+		t.Fatalf("WriteLog failed: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<132>1 ") || !strings.HasSuffix(msg, " hello syslog") {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	if _, err := lm.ReadLogs("", LogFilter{}); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("ReadLogs error = %v, want ErrNotSupported", err)
+	}
+}
+
+// readOctetFrame reads one octet-counted syslog frame
+func readOctetFrame(r *bufio.Reader) (string, error) {
+	size, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(size))
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+func TestSyslogTCPReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer ln.Close()
+
+	frames := make(chan string, 100)
+	go func() {
+		for first := true; ; first = false {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn, closeAfterOne bool) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					frame, err := readOctetFrame(r)
+					if err != nil {
+						return
+					}
+					frames <- frame
+					if closeAfterOne {
+						return
+					}
+				}
+			}(conn, first)
+		}
+	}()
+
+	sb := &SyslogBackend{}
+	if err := sb.Init(testSyslogConfig("tcp", ln.Addr().String())); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer sb.Close()
+
+	if err := sb.Write(LogEntry{Level: LevelInfo, Message: "first", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if frame := <-frames; !strings.HasSuffix(frame, " first") {
+		t.Fatalf("frame = %q", frame)
+	}
+
+	// The server dropped the first connection; writes must reconnect
+	deadline := time.After(5 * time.Second)
+	for {
+		sb.Write(LogEntry{Level: LevelInfo, Message: "again", Timestamp: time.Now()})
+		select {
+		case frame := <-frames:
+			if !strings.HasSuffix(frame, " again") {
+				t.Fatalf("frame = %q", frame)
+			}
+			return
+		case <-deadline:
+			t.Fatal("no message after reconnect")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestSyslogUnixgram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram not available: %v", err)
+	}
+	defer conn.Close()
+
+	sb := &SyslogBackend{}
+	if err := sb.Init(testSyslogConfig("unixgram", path)); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer sb.Close()
+
+	if err := sb.Write(LogEntry{Level: LevelInfo, Message: "local", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if msg := string(buf[:n]); !strings.HasPrefix(msg, "<134>1 ") || !strings.HasSuffix(msg, " local") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
+
+func TestSyslogInvalidConfig(t *testing.T) {
+	for _, config := range []interface{}{
+		FileConfig{},
+		SyslogConfig{Network: "http", Address: "x"},
+		SyslogConfig{Network: "udp"},
+		SyslogConfig{Network: "udp", Address: "127.0.0.1:514", Facility: 24},
+	} {
+		if err := (&SyslogBackend{}).Init(config); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
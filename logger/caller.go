@@ -0,0 +1,43 @@
+// /logger/caller.go
+
+package logger
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+)
+
+// Metadata keys set when Config.CaptureCaller is enabled
+const (
+	CallerKey   = "caller"   // "dir/file.go:line" of the call site
+	FunctionKey = "function" // fully qualified calling function
+)
+
+// callerSkip skips runtime.Callers, withCaller, logManagerImpl.write and
+// the exported write method
+const callerSkip = 4
+
+// withCaller returns a copy of metadata with the caller skip frames up
+// added. Keys the caller already set are kept.
+func withCaller(metadata map[string]interface{}, skip int) map[string]interface{} {
+	var pcs [1]uintptr
+	if runtime.Callers(skip, pcs[:]) == 0 {
+		return metadata
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	out := make(map[string]interface{}, len(metadata)+2)
+	out[CallerKey] = shortCaller(frame.File, frame.Line)
+	out[FunctionKey] = frame.Function
+	for k, v := range metadata {
+		out[k] = v
+	}
+	return out
+}
+
+// shortCaller trims file to its directory and base name
+func shortCaller(file string, line int) string {
+	dir, base := path.Split(file)
+	return fmt.Sprintf("%s:%d", path.Join(path.Base(dir), base), line)
+}
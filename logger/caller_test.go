@@ -0,0 +1,97 @@
+// /logger/caller_test.go
+
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// currentLine returns the line of its call site
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestCaptureCaller(t *testing.T) {
+	lm := newTestManager(t, Config{CaptureCaller: true})
+
+	metadata := map[string]interface{}{"user": "alice"}
+	line := currentLine() + 1
+	lm.WriteLogWithMetadata(LevelError, "failed", metadata)
+	lm.WriteLog(LevelInfo, "plain")
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	want := fmt.Sprintf("logger/caller_test.go:%d", line)
+	if entries[0].Metadata[CallerKey] != want {
+		t.Errorf("caller = %v, want %s", entries[0].Metadata[CallerKey], want)
+	}
+	if fn, _ := entries[0].Metadata[FunctionKey].(string); !strings.HasSuffix(fn, ".TestCaptureCaller") {
+		t.Errorf("function = %v", entries[0].Metadata[FunctionKey])
+	}
+	if entries[0].Metadata["user"] != "alice" || len(metadata) != 1 {
+		t.Errorf("metadata = %v, caller's map = %v", entries[0].Metadata, metadata)
+	}
+	want = fmt.Sprintf("logger/caller_test.go:%d", line+1)
+	if entries[1].Metadata[CallerKey] != want {
+		t.Errorf("caller = %v, want %s", entries[1].Metadata[CallerKey], want)
+	}
+}
+
+// logWarning is a helper whose callers should be reported
+func logWarning(lm LogManager, message string) {
+	lm.WriteLog(LevelWarn, message)
+}
+
+func TestCaptureCallerSkip(t *testing.T) {
+	lm := newTestManager(t, Config{CaptureCaller: true, CallerSkip: 1})
+
+	line := currentLine() + 1
+	logWarning(lm, "wrapped")
+	lm.WriteLogWithMetadata(LevelWarn, "explicit", map[string]interface{}{CallerKey: "main.go:1"})
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	want := fmt.Sprintf("logger/caller_test.go:%d", line)
+	if entries[0].Metadata[CallerKey] != want {
+		t.Errorf("caller = %v, want %s", entries[0].Metadata[CallerKey], want)
+	}
+	if entries[1].Metadata[CallerKey] != "main.go:1" {
+		t.Errorf("explicit caller overwritten: %v", entries[1].Metadata[CallerKey])
+	}
+}
+
+func TestMinLevel(t *testing.T) {
+	lm := newTestManager(t, Config{MinLevel: LevelWarn, CaptureCaller: true})
+
+	if lm.Enabled(LevelInfo) || !lm.Enabled(LevelWarn) || !lm.Enabled(LevelError) {
+		t.Error("unexpected Enabled result")
+	}
+	lm.WriteLog(LevelDebug, "debug")
+	lm.WriteLog(LevelInfo, "info")
+	lm.WriteLog(LevelError, "error")
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "error")
+	if n := lm.Stats().Accepted[LevelInfo]; n != 0 {
+		t.Errorf("accepted %d discarded entries", n)
+	}
+
+	if _, err := NewLogManager(Config{Backend: BackendMemory, BackendConfig: MemoryConfig{}, MinLevel: "LOUD"}); err == nil {
+		t.Error("expected error for unknown minimum level")
+	}
+}
+
+func BenchmarkWriteLogDisabled(b *testing.B) {
+	lm, _ := NewLogManager(Config{Backend: BackendMemory, BackendConfig: MemoryConfig{}, MinLevel: LevelInfo, CaptureCaller: true})
+	defer lm.Close()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lm.WriteLog(LevelDebug, "skipped")
+	}
+}
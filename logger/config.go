@@ -1,35 +1,139 @@
 // pkg/logger/config.go
 package logger
 
+import (
+	"io"
+	"time"
+)
+
 // BackendType defines the storage backend for logs
 type BackendType string
 
 const (
-	BackendFile BackendType = "file"
-	BackendSQL  BackendType = "sql"
+	BackendFile    BackendType = "file"
+	BackendSQL     BackendType = "sql"
+	BackendSyslog  BackendType = "syslog"
+	BackendMemory  BackendType = "memory"
+	BackendConsole BackendType = "console"
 )
 
 // Config is the main configuration for LogManager
 type Config struct {
 	Backend       BackendType
-	BackendConfig interface{} // FileConfig or SQLConfig
+	BackendConfig interface{} // the config type matching Backend, e.g. FileConfig
 
 	// Common settings
-	Async        bool
+	Async    bool
+	MinLevel LogLevel // entries below are discarded before any work, empty keeps all
+
+	// Deprecated: DefaultLevel is not read by the LogManager; set MinLevel
+	// to discard entries below a level.
 	DefaultLevel LogLevel
+
+	// Caller capture: record the call site of WriteLog under CallerKey and
+	// FunctionKey. CallerSkip skips additional frames, e.g. 1 for a helper
+	// wrapping WriteLog.
+	CaptureCaller bool
+	CallerSkip    int
+
+	// StackTraceLevel attaches the stack of the call site under StackKey to
+	// entries at or above it. Default LevelError; DisableStackTraces turns
+	// it off. Error metadata values are expanded into their cause chain at
+	// every level.
+	StackTraceLevel    LogLevel
+	DisableStackTraces bool
+
+	// Exit is called with status 1 after a FATAL entry has been written and
+	// the manager closed. Default os.Exit.
+	Exit func(code int)
+
+	// Handler dispatch: every handler runs on its own goroutine behind a
+	// bounded queue, also without Async, so Handle may see an entry after
+	// WriteLog has returned. Zero values use the defaults below.
+	HandlerQueueSize   int           // entries queued per handler, default 256
+	HandlerTimeout     time.Duration // limit for one Handle call before the next entry waits, default 5s
+	HandlerMaxFailures int           // consecutive failures before a handler is disabled, default 5
+
+	// OnHandlerError is called with handler errors, panics, timeouts and
+	// dropped entries. It must not block.
+	OnHandlerError func(id HandlerID, err error)
+
+	// Backend write resilience
+	Retry                RetryPolicy
+	CircuitBreaker       BreakerConfig
+	OnBreakerStateChange func(from, to BreakerState)
+
+	// Flight recorder: buffer low severity entries until a trigger entry
+	FlightRecorder FlightRecorderConfig
 }
 
+const (
+	defaultHandlerQueueSize   = 256
+	defaultHandlerTimeout     = 5 * time.Second
+	defaultHandlerMaxFailures = 5
+)
+
 // FileConfig contains file backend specific settings
 type FileConfig struct {
 	FilePath      string
 	MaxFileSizeMB int
+
+	// Format names the line format, "text" by default; see RegisterFormat
+	Format string
+	// Layout customizes the text format with a template such as
+	// "%{time:2006-01-02 15:04:05.000} [%{level}] %{message} %{fields}";
+	// see NewLayoutFormat
+	Layout string
+	// Strict makes reads fail on lines the format cannot decode. Otherwise
+	// they are skipped, counted in LogStats.MalformedLines and passed to
+	// OnMalformedLine when set.
+	Strict          bool
+	OnMalformedLine func(err *MalformedLineError)
 }
 
-// SQLConfig contains SQL backend specific settings
+// SQLConfig contains SQL backend specific settings. The application must
+// import a database/sql driver registered under Driver, otherwise
+// NewLogManager fails.
 type SQLConfig struct {
 	DSN       string // e.g., "user:password@tcp(localhost:3306)/dbname"
 	TableName string // default: "logs"
-	Driver    string // "mysql", "postgres", "sqlite"
+	Driver    string // "mysql", "postgres" or "pgx", anything else is treated as SQLite
+}
+
+// SyslogConfig contains syslog backend specific settings
+type SyslogConfig struct {
+	Network  string // "udp", "tcp" or "unixgram"
+	Address  string // host:port, or the socket path for unixgram (e.g. /dev/log)
+	Facility int    // 0 is FacilityKern; DefaultSyslogConfig uses FacilityUser
+
+	Hostname         string        // default os.Hostname()
+	AppName          string        // default the executable name
+	ProcID           string        // default the process id
+	MsgID            string        // optional
+	StructuredDataID string        // SD-ID carrying Metadata, default "meta@32473"
+	Timeout          time.Duration // dial and write timeout, default 5s
+}
+
+// MemoryConfig contains memory backend settings; entries beyond either
+// limit evict the oldest. Without limits the last 10000 entries are kept.
+type MemoryConfig struct {
+	MaxEntries int
+	MaxBytes   int // approximate, counting message, level and metadata
+}
+
+// ConsoleConfig contains console backend settings
+type ConsoleConfig struct {
+	Output       io.Writer // default os.Stdout
+	ErrorOutput  io.Writer // default os.Stderr
+	StderrLevel  LogLevel  // entries at least this severe go to ErrorOutput; "" disables
+	Color        ColorMode // default ColorAuto
+	TimeFormat   string    // default "15:04:05.000"
+	MessageWidth int       // messages are padded to this width before metadata, default 40
+}
+
+// DefaultConsoleConfig returns a colored console sending ERROR to stderr
+func DefaultConsoleConfig() ConsoleConfig {
+	return ConsoleConfig{StderrLevel: LevelError}
 }
 
 // DefaultFileConfig returns default file configuration
@@ -40,6 +144,15 @@ func DefaultFileConfig() FileConfig {
 	}
 }
 
+// DefaultSyslogConfig returns a UDP syslog configuration with the user facility
+func DefaultSyslogConfig(address string) SyslogConfig {
+	return SyslogConfig{
+		Network:  "udp",
+		Address:  address,
+		Facility: FacilityUser,
+	}
+}
+
 // DefaultSQLConfig returns default SQL configuration
 func DefaultSQLConfig(dsn string) SQLConfig {
 	return SQLConfig{
@@ -0,0 +1,336 @@
+// /logger/encoding.go
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in line formats
+const (
+	FormatText   = "text"   // [2025-01-01T12:00:00Z] INFO : message<TAB>{"key":"value"}
+	FormatJSON   = "json"   // {"level":"INFO","message":"...","timestamp":"...","metadata":{...}}
+	FormatLogfmt = "logfmt" // ts=2025-01-01T12:00:00Z level=INFO msg="..." key=value
+)
+
+// Encoder renders an entry as a single line without the trailing newline
+type Encoder interface {
+	Encode(entry LogEntry) ([]byte, error)
+}
+
+// Decoder parses a line produced by the matching Encoder
+type Decoder interface {
+	Decode(line []byte) (LogEntry, error)
+}
+
+// Format pairs an Encoder with the Decoder reading its output back
+type Format struct {
+	Encoder Encoder
+	Decoder Decoder
+}
+
+// MalformedLineError reports a line its Decoder could not parse
+type MalformedLineError struct {
+	Offset int64 // byte offset of the line in the file
+	Line   string
+	Err    error
+}
+
+func (e *MalformedLineError) Error() string {
+	return fmt.Sprintf("malformed log line at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *MalformedLineError) Unwrap() error { return e.Err }
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]Format{
+		FormatText:   {Encoder: textFormat{}, Decoder: textFormat{}},
+		FormatJSON:   {Encoder: jsonFormat{}, Decoder: jsonFormat{}},
+		FormatLogfmt: {Encoder: logfmtFormat{}, Decoder: logfmtFormat{}},
+	}
+)
+
+// RegisterFormat makes a format selectable by name in FileConfig.Format
+func RegisterFormat(name string, format Format) error {
+	if name == "" || format.Encoder == nil || format.Decoder == nil {
+		return errors.New("format needs a name, an encoder and a decoder")
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, ok := formats[name]; ok {
+		return fmt.Errorf("format already registered: %s", name)
+	}
+	formats[name] = format
+	return nil
+}
+
+// LookupFormat returns the format registered under name
+func LookupFormat(name string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	format, ok := formats[name]
+	return format, ok
+}
+
+// textFormat is the original file layout with metadata appended as a tab
+// separated JSON object
+type textFormat struct{}
+
+func (textFormat) Encode(entry LogEntry) ([]byte, error) {
+	timestamp := entry.Timestamp.Format(time.RFC3339)
+	line := fmt.Sprintf("[%s] %-5s: %s", timestamp, entry.Level, entry.Message)
+
+	if len(entry.Metadata) > 0 {
+		data, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		line += "\t" + string(data)
+	}
+	return []byte(line), nil
+}
+
+func (textFormat) Decode(data []byte) (LogEntry, error) {
+	line := string(data)
+
+	var metadata map[string]interface{}
+	if i := strings.LastIndex(line, "\t{"); i != -1 {
+		if err := json.Unmarshal([]byte(line[i+1:]), &metadata); err == nil {
+			line = line[:i]
+		} else {
+			metadata = nil
+		}
+	}
+
+	if !strings.HasPrefix(line, "[") {
+		return LogEntry{}, errors.New("missing timestamp")
+	}
+
+	end := strings.Index(line, "]")
+	if end == -1 {
+		return LogEntry{}, errors.New("unterminated timestamp")
+	}
+
+	ts, err := time.Parse(time.RFC3339, line[1:end])
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	rest := strings.TrimSpace(line[end+1:])
+	parts := strings.SplitN(rest, ":", 2)
+	if len(parts) != 2 {
+		return LogEntry{}, errors.New("missing level separator")
+	}
+
+	return LogEntry{
+		Timestamp: ts,
+		Level:     LogLevel(strings.TrimSpace(parts[0])),
+		Message:   strings.TrimSpace(parts[1]),
+		Metadata:  metadata,
+	}, nil
+}
+
+// jsonFormat writes one JSON object per line
+type jsonFormat struct{}
+
+func (jsonFormat) Encode(entry LogEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entry: %w", err)
+	}
+	return data, nil
+}
+
+func (jsonFormat) Decode(line []byte) (LogEntry, error) {
+	var entry LogEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return LogEntry{}, err
+	}
+	if entry.Level == "" || entry.Timestamp.IsZero() {
+		return LogEntry{}, errors.New("missing level or timestamp")
+	}
+	return entry, nil
+}
+
+// logfmtFormat writes key=value pairs: ts, level and msg, then the metadata
+// keys in sorted order. Metadata strings are written bare when possible and
+// other values as JSON, so numbers and booleans read back as float64 and bool.
+// Strings holding a JSON object or array read back decoded.
+type logfmtFormat struct{}
+
+func (logfmtFormat) Encode(entry LogEntry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("ts=" + entry.Timestamp.Format(time.RFC3339Nano))
+	b.WriteString(" level=" + logfmtValue(string(entry.Level)))
+	b.WriteString(" msg=" + logfmtValue(entry.Message))
+
+	keys := make([]string, 0, len(entry.Metadata))
+	for k := range entry.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if k == "" || strings.ContainsAny(k, " =\"\t\n") {
+			return nil, fmt.Errorf("invalid logfmt key: %q", k)
+		}
+		value, err := logfmtEncodeValue(entry.Metadata[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode metadata %s: %w", k, err)
+		}
+		b.WriteString(" " + k + "=" + value)
+	}
+	return b.Bytes(), nil
+}
+
+// logfmtEncodeValue writes strings bare when possible and other values as
+// JSON, quoting either when needed
+func logfmtEncodeValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		value := logfmtValue(s)
+		if value == s && logfmtLiteral(s) {
+			// Would read back as a number, bool or null
+			value = strconv.Quote(s)
+		}
+		return value, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return logfmtValue(string(data)), nil
+}
+
+// logfmtValue quotes s when it is empty or contains separators
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logfmtLiteral reports whether a bare value decodes to a non-string
+func logfmtLiteral(s string) bool {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return false
+	}
+	_, isString := v.(string)
+	return !isString
+}
+
+func (logfmtFormat) Decode(line []byte) (LogEntry, error) {
+	var entry LogEntry
+	var haveTime, haveMsg bool
+
+	err := parseLogfmt(string(line), func(key, raw string, quoted bool) error {
+		switch {
+		case key == "ts" && !haveTime:
+			ts, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return fmt.Errorf("invalid timestamp: %w", err)
+			}
+			entry.Timestamp, haveTime = ts, true
+		case key == "level" && entry.Level == "":
+			entry.Level = LogLevel(raw)
+		case key == "msg" && !haveMsg:
+			entry.Message, haveMsg = raw, true
+		default:
+			if entry.Metadata == nil {
+				entry.Metadata = make(map[string]interface{})
+			}
+			entry.Metadata[key] = logfmtDecodeValue(raw, quoted)
+		}
+		return nil
+	})
+	if err != nil {
+		return LogEntry{}, err
+	}
+
+	if !haveTime || entry.Level == "" {
+		return LogEntry{}, errors.New("missing ts or level")
+	}
+	return entry, nil
+}
+
+// parseLogfmt calls fn for each key=value pair of s with quoted values
+// already unquoted
+func parseLogfmt(s string, fn func(key, raw string, quoted bool) error) error {
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			break
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return fmt.Errorf("expected key=value near %q", s)
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var raw string
+		quoted := strings.HasPrefix(s, `"`)
+		if quoted {
+			end := logfmtQuoteEnd(s)
+			if end == -1 {
+				return fmt.Errorf("unterminated value for %s", key)
+			}
+			v, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			raw, s = v, s[end+1:]
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end == -1 {
+				end = len(s)
+			}
+			raw, s = s[:end], s[end:]
+		}
+
+		if err := fn(key, raw, quoted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logfmtQuoteEnd returns the index of the closing quote of s[0]
+func logfmtQuoteEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// logfmtDecodeValue turns a value back into what the encoder was given:
+// quoted values and bare words are strings, JSON literals are decoded
+func logfmtDecodeValue(raw string, quoted bool) interface{} {
+	if !quoted || strings.HasPrefix(raw, "{") || strings.HasPrefix(raw, "[") {
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err == nil {
+			if _, isString := v.(string); !isString {
+				return v
+			}
+		}
+	}
+	return raw
+}
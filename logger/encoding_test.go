@@ -0,0 +1,205 @@
+// /logger/encoding_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	entry := LogEntry{
+		Level:     LevelWarn,
+		Message:   `disk "data" almost full`,
+		Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Metadata: map[string]interface{}{
+			"host":   "db1",
+			"path":   "/var/lib data",
+			"used":   97.5,
+			"ok":     false,
+			"code":   "42",
+			"labels": map[string]interface{}{"team": "storage"},
+		},
+	}
+
+	for _, name := range []string{FormatText, FormatJSON, FormatLogfmt} {
+		format, ok := LookupFormat(name)
+		if !ok {
+			t.Fatalf("format %s not registered", name)
+		}
+		line, err := format.Encoder.Encode(entry)
+		if err != nil {
+			t.Fatalf("%s: Encode failed: %v", name, err)
+		}
+		if strings.Contains(string(line), "\n") {
+			t.Errorf("%s: encoded line contains a newline: %q", name, line)
+		}
+		got, err := format.Decoder.Decode(line)
+		if err != nil {
+			t.Fatalf("%s: Decode(%q) failed: %v", name, line, err)
+		}
+		if !got.Timestamp.Equal(entry.Timestamp) || got.Level != entry.Level ||
+			got.Message != entry.Message || !reflect.DeepEqual(got.Metadata, entry.Metadata) {
+			t.Errorf("%s: round trip of %q =\n%+v\nwant\n%+v", name, line, got, entry)
+		}
+	}
+}
+
+func TestLogfmtEncoding(t *testing.T) {
+	line, _ := logfmtFormat{}.Encode(LogEntry{
+		Level:     LevelInfo,
+		Message:   "user login",
+		Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Metadata:  map[string]interface{}{"user": "alice", "attempts": 3, "msg": "shadowed"},
+	})
+	want := `ts=2024-05-01T10:00:00Z level=INFO msg="user login" attempts=3 msg=shadowed user=alice`
+	if string(line) != want {
+		t.Errorf("logfmt = %s, want %s", line, want)
+	}
+	entry, err := logfmtFormat{}.Decode(line)
+	if err != nil || entry.Message != "user login" || entry.Metadata["msg"] != "shadowed" {
+		t.Errorf("decoded %+v, %v", entry, err)
+	}
+
+	for _, bad := range []string{
+		`level=INFO msg=x`,
+		`ts=yesterday level=INFO`,
+		`ts=2024-05-01T10:00:00Z level=INFO msg="open`,
+		`ts=2024-05-01T10:00:00Z level=INFO junk`,
+	} {
+		if _, err := (logfmtFormat{}).Decode([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFileBackendFormats(t *testing.T) {
+	for _, name := range []string{FormatJSON, FormatLogfmt} {
+		fb := &FileBackend{}
+		if err := fb.Init(FileConfig{FilePath: t.TempDir() + "/app.log", Format: name}); err != nil {
+			t.Fatalf("Init failed: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			fb.Write(LogEntry{Level: LevelInfo, Message: fmt.Sprintf("msg %d", i), Timestamp: time.Now(),
+				Metadata: map[string]interface{}{"n": i}})
+		}
+		entries, err := fb.Read("", LogFilter{Metadata: []MetadataPredicate{{Key: "n", Op: OpGe, Value: 1}}})
+		if err != nil {
+			t.Fatalf("%s: Read failed: %v", name, err)
+		}
+		expectMessages(t, entries, "msg 1", "msg 2")
+		fb.Close()
+	}
+
+	if err := (&FileBackend{}).Init(FileConfig{FilePath: t.TempDir() + "/app.log", Format: "yaml"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestFileBackendMalformedLines(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	var reported []*MalformedLineError
+	fb := &FileBackend{}
+	fb.Init(FileConfig{FilePath: path, OnMalformedLine: func(err *MalformedLineError) {
+		reported = append(reported, err)
+	}})
+	defer fb.Close()
+
+	fb.Write(LogEntry{Level: LevelInfo, Message: "before", Timestamp: time.Now()})
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("garbage line\n")
+	f.Close()
+	fb.Write(LogEntry{Level: LevelInfo, Message: "after", Timestamp: time.Now()})
+
+	entries, err := fb.Read("", LogFilter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	expectMessages(t, entries, "before", "after")
+	if len(reported) != 1 || reported[0].Line != "garbage line" || reported[0].Offset == 0 {
+		t.Fatalf("reported = %+v", reported)
+	}
+	if n := fb.MalformedLines(); n != 1 {
+		t.Errorf("MalformedLines = %d, want 1", n)
+	}
+
+	fb.config.Strict = true
+	_, err = fb.Read("", LogFilter{})
+	var malformed *MalformedLineError
+	if !errors.As(err, &malformed) || malformed.Offset != reported[0].Offset {
+		t.Fatalf("expected MalformedLineError, got %v", err)
+	}
+
+	var streamErr error
+	for _, err := range fb.Stream(t.Context(), "", LogFilter{}) {
+		if err != nil {
+			streamErr = err
+		}
+	}
+	if !errors.As(streamErr, &malformed) {
+		t.Errorf("expected Stream to yield MalformedLineError, got %v", streamErr)
+	}
+}
+
+func TestMalformedLinesInStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lm := newTestManager(t, Config{Backend: BackendFile, BackendConfig: FileConfig{FilePath: path}})
+
+	lm.WriteLog(LevelInfo, "before")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("garbage line\n")
+	f.Close()
+
+	entries, err := lm.ReadLogs("", LogFilter{})
+	if err != nil {
+		t.Fatalf("ReadLogs failed: %v", err)
+	}
+	expectMessages(t, entries, "before")
+	if n := lm.Stats().MalformedLines; n != 1 {
+		t.Errorf("MalformedLines = %d, want 1", n)
+	}
+}
+
+type upperFormat struct{}
+
+func (upperFormat) Encode(entry LogEntry) ([]byte, error) {
+	return []byte(entry.Timestamp.Format(time.RFC3339) + "|" + string(entry.Level) + "|" + strings.ToUpper(entry.Message)), nil
+}
+
+func (upperFormat) Decode(line []byte) (LogEntry, error) {
+	parts := strings.SplitN(string(line), "|", 3)
+	if len(parts) != 3 {
+		return LogEntry{}, errors.New("expected 3 fields")
+	}
+	ts, err := time.Parse(time.RFC3339, parts[0])
+	return LogEntry{Timestamp: ts, Level: LogLevel(parts[1]), Message: parts[2]}, err
+}
+
+func TestRegisterFormat(t *testing.T) {
+	if _, ok := LookupFormat("upper-test"); !ok {
+		if err := RegisterFormat("upper-test", Format{Encoder: upperFormat{}, Decoder: upperFormat{}}); err != nil {
+			t.Fatalf("RegisterFormat failed: %v", err)
+		}
+	}
+	if err := RegisterFormat("upper-test", Format{Encoder: upperFormat{}, Decoder: upperFormat{}}); err == nil {
+		t.Error("expected error for duplicate format")
+	}
+	if err := RegisterFormat("incomplete", Format{Encoder: upperFormat{}}); err == nil {
+		t.Error("expected error without decoder")
+	}
+
+	fb := &FileBackend{}
+	if err := fb.Init(FileConfig{FilePath: t.TempDir() + "/app.log", Format: "upper-test"}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer fb.Close()
+	fb.Write(LogEntry{Level: LevelInfo, Message: "hello", Timestamp: time.Now()})
+	entries, _ := fb.Read("", LogFilter{})
+	expectMessages(t, entries, "HELLO")
+}
@@ -0,0 +1,257 @@
+// /logger/filter.go
+
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CompareOp is a comparison operator used by MetadataPredicate
+type CompareOp string
+
+const (
+	OpEq CompareOp = "="
+	OpNe CompareOp = "!="
+	OpLt CompareOp = "<"
+	OpLe CompareOp = "<="
+	OpGt CompareOp = ">"
+	OpGe CompareOp = ">="
+)
+
+// MetadataPredicate compares the metadata value stored under Key with Value.
+// Numeric values (int, float, ...) compare numerically; anything else
+// compares as a string. Entries without the key never match.
+type MetadataPredicate struct {
+	Key   string
+	Op    CompareOp
+	Value interface{}
+}
+
+// entryMatcher is a LogFilter compiled for repeated evaluation
+type entryMatcher struct {
+	level  LogLevel
+	filter LogFilter
+	levels map[LogLevel]bool // nil means any level
+	regex  *regexp.Regexp
+	values []float64 // numeric form of each metadata predicate value
+	isNum  []bool
+}
+
+// newEntryMatcher compiles the level argument of Read together with filter
+func newEntryMatcher(level LogLevel, filter LogFilter) (*entryMatcher, error) {
+	m := &entryMatcher{level: level, filter: filter}
+
+	if filter.MinLevel != "" {
+		if _, ok := filter.MinLevel.Severity(); !ok {
+			return nil, fmt.Errorf("unknown minimum level: %s", filter.MinLevel)
+		}
+	}
+	if len(filter.Levels) > 0 || filter.MinLevel != "" {
+		m.levels = allowedLevels(filter)
+	}
+
+	if filter.Regex != "" {
+		pattern := filter.Regex
+		if filter.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex filter: %w", err)
+		}
+		m.regex = re
+	}
+
+	for _, p := range filter.Metadata {
+		if p.Key == "" {
+			return nil, fmt.Errorf("metadata predicate without key")
+		}
+		switch p.Op {
+		case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+		default:
+			return nil, fmt.Errorf("unsupported metadata operator: %q", p.Op)
+		}
+		f, ok := numericValue(p.Value, false)
+		m.values = append(m.values, f)
+		m.isNum = append(m.isNum, ok)
+	}
+
+	return m, nil
+}
+
+// allowedLevels intersects filter.Levels with the levels allowed by MinLevel
+func allowedLevels(filter LogFilter) map[LogLevel]bool {
+	allowed := make(map[LogLevel]bool)
+	if len(filter.Levels) > 0 {
+		for _, l := range filter.Levels {
+			allowed[l] = true
+		}
+	}
+	if filter.MinLevel != "" {
+		atLeast := make(map[LogLevel]bool)
+		for _, l := range levelsAtLeast(filter.MinLevel) {
+			if len(filter.Levels) == 0 || allowed[l] {
+				atLeast[l] = true
+			}
+		}
+		allowed = atLeast
+	}
+	return allowed
+}
+
+// match reports whether entry passes the level argument and the filter.
+// SQLBackend generates the equivalent WHERE clause in sqlQuery.filter.
+func (m *entryMatcher) match(entry LogEntry) bool {
+	filter := m.filter
+
+	// Filter by level
+	if m.level != "" && entry.Level != m.level {
+		return false
+	}
+	if m.levels != nil && !m.levels[entry.Level] {
+		return false
+	}
+
+	// Filter by keyword (Contains)
+	if filter.Contains != "" && !containsText(entry.Message, filter.Contains, filter.IgnoreCase) {
+		return false
+	}
+
+	// Filter by excluded keywords
+	for _, term := range filter.Excludes {
+		if term != "" && containsText(entry.Message, term, filter.IgnoreCase) {
+			return false
+		}
+	}
+
+	if m.regex != nil && !m.regex.MatchString(entry.Message) {
+		return false
+	}
+
+	// Filter: StartTime (entry must be AFTER start)
+	if filter.StartTime != nil && entry.Timestamp.Before(*filter.StartTime) {
+		return false
+	}
+
+	// Filter: EndTime (entry must be BEFORE end)
+	if filter.EndTime != nil && entry.Timestamp.After(*filter.EndTime) {
+		return false
+	}
+
+	for i, p := range filter.Metadata {
+		if !matchMetadata(entry, p, m.values[i], m.isNum[i]) {
+			return false
+		}
+	}
+
+	// Filter by parsed query expression
+	if filter.Expr != nil && !filter.Expr.Eval(entry) {
+		return false
+	}
+
+	return true
+}
+
+// matchMetadata evaluates p against entry; num is the numeric form of
+// p.Value when isNum is set. Numeric predicates only match numeric values,
+// as the SQL backend only compares JSON numbers with them.
+func matchMetadata(entry LogEntry, p MetadataPredicate, num float64, isNum bool) bool {
+	v, ok := entry.Metadata[p.Key]
+	if !ok || v == nil {
+		return false
+	}
+
+	var cmp int
+	if isNum {
+		f, ok := numericValue(v, false)
+		if !ok {
+			return false
+		}
+		switch {
+		case f < num:
+			cmp = -1
+		case f > num:
+			cmp = 1
+		}
+	} else {
+		cmp = strings.Compare(metadataText(v), metadataText(p.Value))
+	}
+
+	switch p.Op {
+	case OpEq:
+		return cmp == 0
+	case OpNe:
+		return cmp != 0
+	case OpLt:
+		return cmp < 0
+	case OpLe:
+		return cmp <= 0
+	case OpGt:
+		return cmp > 0
+	case OpGe:
+		return cmp >= 0
+	}
+	return false
+}
+
+func containsText(s, substr string, ignoreCase bool) bool {
+	if ignoreCase {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+	}
+	return strings.Contains(s, substr)
+}
+
+// metadataText formats a metadata value the way the SQL backends render
+// JSON values as text, so numbers read back as float64 from a file still
+// compare and group like the integers that were written
+func metadataText(v interface{}) string {
+	switch n := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	// Integers and bools already print as JSON does
+	return fmt.Sprint(v)
+}
+
+// numericValue converts v to float64 if it is a number. Strings are only
+// parsed when parseStrings is set, so that predicate values given as strings
+// keep string comparison semantics.
+func numericValue(v interface{}, parseStrings bool) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		if parseStrings {
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
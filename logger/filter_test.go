@@ -0,0 +1,85 @@
+// /logger/filter_test.go
+
+package logger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEntryMatcher(t *testing.T) {
+	entry := LogEntry{
+		Level:     LevelWarn,
+		Message:   "Request Timeout 250ms",
+		Timestamp: time.Now(),
+		Metadata:  map[string]interface{}{"component": "scheduler", "latency": float64(250)},
+	}
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   bool
+	}{
+		{"empty", LogFilter{}, true},
+		{"min level", LogFilter{MinLevel: LevelWarn}, true},
+		{"min level above", LogFilter{MinLevel: LevelError}, false},
+		{"level set", LogFilter{Levels: []LogLevel{LevelInfo, LevelWarn}}, true},
+		{"level set without", LogFilter{Levels: []LogLevel{LevelInfo}}, false},
+		{"contains case", LogFilter{Contains: "timeout"}, false},
+		{"contains ignore case", LogFilter{Contains: "timeout", IgnoreCase: true}, true},
+		{"regex", LogFilter{Regex: `Timeout \d+ms`}, true},
+		{"regex ignore case", LogFilter{Regex: `timeout \d+ms`, IgnoreCase: true}, true},
+		{"exclude", LogFilter{Excludes: []string{"Request"}}, false},
+		{"meta eq", LogFilter{Metadata: []MetadataPredicate{{Key: "component", Op: OpEq, Value: "scheduler"}}}, true},
+		{"meta ne", LogFilter{Metadata: []MetadataPredicate{{Key: "component", Op: OpNe, Value: "scheduler"}}}, false},
+		{"meta range", LogFilter{Metadata: []MetadataPredicate{{Key: "latency", Op: OpGe, Value: 200}}}, true},
+		{"meta range above", LogFilter{Metadata: []MetadataPredicate{{Key: "latency", Op: OpGt, Value: 250}}}, false},
+		{"meta missing", LogFilter{Metadata: []MetadataPredicate{{Key: "node", Op: OpNe, Value: "x"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newEntryMatcher("", tt.filter)
+			if err != nil {
+				t.Fatalf("newEntryMatcher failed: %v", err)
+			}
+			if got := m.match(entry); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryMatcherInvalid(t *testing.T) {
+	if _, err := newEntryMatcher("", LogFilter{Regex: "("}); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	if _, err := newEntryMatcher("", LogFilter{Metadata: []MetadataPredicate{{Key: "a", Op: "~"}}}); err == nil {
+		t.Error("Expected error for unsupported operator")
+	}
+	if _, err := newEntryMatcher("", LogFilter{MinLevel: "WARNING"}); err == nil {
+		t.Error("Expected error for unknown minimum level")
+	}
+}
+
+func TestSQLFilterClause(t *testing.T) {
+	q := &sqlQuery{dialect: sqlDialect{driver: "postgres"}}
+	q.filter("", LogFilter{
+		MinLevel:   LevelWarn,
+		Regex:      `timeout \d+ms`,
+		IgnoreCase: true,
+		Excludes:   []string{"retry"},
+		Metadata:   []MetadataPredicate{{Key: "component", Op: OpEq, Value: "scheduler"}, {Key: "latency", Op: OpGe, Value: 200}},
+	})
+
+	want := "WHERE level IN ($1, $2, $3, $4) AND NOT (LOWER(message) LIKE $5 ESCAPE '!') AND message ~* $6 AND " +
+		"(metadata::jsonb ->> $7) = $8 AND (CASE WHEN jsonb_typeof(metadata::jsonb -> $9) = 'number' " +
+		"THEN (metadata::jsonb ->> $10)::DOUBLE PRECISION END) >= $11"
+	if got := q.whereClause(); got != " "+want {
+		t.Errorf("Unexpected clause:\n got:%s\nwant: %s", got, want)
+	}
+	if fmt.Sprint(q.args) != `[ERROR FATAL PANIC WARN %retry% timeout \d+ms component scheduler latency latency 200]` {
+		t.Errorf("Unexpected args: %v", q.args)
+	}
+}
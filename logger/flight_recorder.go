@@ -0,0 +1,183 @@
+// /logger/flight_recorder.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	// errFlightRecorderFull is recorded for entries pushed out of a full
+	// buffer and for the entries of an evicted buffer
+	errFlightRecorderFull = errors.New("flight recorder buffer full, entry dropped")
+	// errFlightRecorderClosed is recorded for entries still buffered at Close
+	errFlightRecorderClosed = errors.New("flight recorder closed with buffered entries")
+)
+
+// FlightRecorderConfig holds low severity entries in memory instead of
+// writing them, and writes the related ones just before an entry at or above
+// Trigger. Setting BufferBelow enables it.
+type FlightRecorderConfig struct {
+	BufferBelow LogLevel // entries less severe than this are buffered, e.g. LevelInfo
+	Trigger     LogLevel // flushes the buffer, default LevelError
+	MaxEntries  int      // per buffer, oldest dropped first, default 1000
+
+	// Entries dropped from a full buffer or with an evicted one are counted
+	// in LogStats.Dropped
+
+	// KeyField groups buffers by this metadata value (e.g. "trace_id"), so a
+	// trigger only flushes entries of its own request. Entries without the
+	// key share one buffer.
+	KeyField string
+	MaxKeys  int // buffers kept, least recently used dropped first, default 1000
+
+	// FlushOnClose writes the entries still buffered when the LogManager is
+	// closed. Otherwise they are discarded and counted in LogStats.Dropped.
+	FlushOnClose bool
+}
+
+type flightRecorder struct {
+	mu      sync.Mutex
+	config  FlightRecorderConfig
+	below   int // severity of BufferBelow
+	trigger int // severity of Trigger
+	rings   map[string]*flightRing
+	seq     uint64
+}
+
+type flightRing struct {
+	entries  []LogEntry
+	lastUsed uint64
+}
+
+func newFlightRecorder(config FlightRecorderConfig) (*flightRecorder, error) {
+	if config.Trigger == "" {
+		config.Trigger = LevelError
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 1000
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = 1000
+	}
+
+	below, ok := config.BufferBelow.Severity()
+	if !ok {
+		return nil, fmt.Errorf("unknown flight recorder level: %s", config.BufferBelow)
+	}
+	trigger, ok := config.Trigger.Severity()
+	if !ok {
+		return nil, fmt.Errorf("unknown flight recorder trigger level: %s", config.Trigger)
+	}
+	if trigger < below {
+		return nil, fmt.Errorf("flight recorder trigger %s is below the buffered levels", config.Trigger)
+	}
+
+	return &flightRecorder{
+		config:  config,
+		below:   below,
+		trigger: trigger,
+		rings:   make(map[string]*flightRing),
+	}, nil
+}
+
+// key returns the buffer key of entry
+func (fr *flightRecorder) key(entry LogEntry) string {
+	if fr.config.KeyField == "" {
+		return ""
+	}
+	if v, ok := entry.Metadata[fr.config.KeyField]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// record buffers entry when it is below the threshold and reports whether it
+// did, with the entries dropped to make room. For a trigger entry it returns
+// the buffered entries to write first.
+func (fr *flightRecorder) record(entry LogEntry) (buffered bool, flush, dropped []LogEntry) {
+	sev, ok := entry.Level.Severity()
+	if !ok {
+		return false, nil, nil
+	}
+
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	key := fr.key(entry)
+	switch {
+	case sev < fr.below:
+		fr.seq++
+		ring, ok := fr.rings[key]
+		if !ok {
+			if len(fr.rings) >= fr.config.MaxKeys {
+				dropped = fr.evictOldest()
+			}
+			ring = &flightRing{}
+			fr.rings[key] = ring
+		}
+		ring.lastUsed = fr.seq
+		if len(ring.entries) >= fr.config.MaxEntries {
+			dropped = append(dropped, ring.entries[0])
+			ring.entries[0] = LogEntry{}
+			ring.entries = ring.entries[1:]
+		}
+		ring.entries = append(ring.entries, entry)
+		return true, nil, dropped
+
+	case sev >= fr.trigger:
+		if ring, ok := fr.rings[key]; ok {
+			delete(fr.rings, key)
+			return false, ring.entries, nil
+		}
+	}
+	return false, nil, nil
+}
+
+// evictOldest drops the least recently used buffer and returns its entries;
+// fr.mu must be held
+func (fr *flightRecorder) evictOldest() []LogEntry {
+	oldest := ""
+	var oldestUsed uint64
+	first := true
+	for key, ring := range fr.rings {
+		if first || ring.lastUsed < oldestUsed {
+			oldest, oldestUsed, first = key, ring.lastUsed, false
+		}
+	}
+	entries := fr.rings[oldest].entries
+	delete(fr.rings, oldest)
+	return entries
+}
+
+// buffered returns the number of entries held
+func (fr *flightRecorder) buffered() int {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	n := 0
+	for _, ring := range fr.rings {
+		n += len(ring.entries)
+	}
+	return n
+}
+
+// drain empties every buffer and returns the entries ordered by time
+func (fr *flightRecorder) drain() []LogEntry {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+
+	var entries []LogEntry
+	for _, ring := range fr.rings {
+		entries = append(entries, ring.entries...)
+	}
+	fr.rings = make(map[string]*flightRing)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries
+}
@@ -0,0 +1,105 @@
+// /logger/flight_recorder_test.go
+
+package logger
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	lm := newTestManager(t, Config{FlightRecorder: FlightRecorderConfig{BufferBelow: LevelInfo, MaxEntries: 2}})
+
+	lm.WriteLog(LevelDebug, "d1")
+	lm.WriteLog(LevelDebug, "d2")
+	lm.WriteLog(LevelInfo, "i1")
+	lm.WriteLog(LevelDebug, "d3")
+	lm.WriteLog(LevelWarn, "w1") // not a trigger
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "i1", "w1")
+	if n := lm.Stats().FlightRecorded; n != 2 {
+		t.Errorf("buffered = %d, want 2", n)
+	}
+	// d1 was pushed out by d3
+	if stats := lm.Stats(); stats.Dropped[LevelDebug] != 1 || stats.LastError != errFlightRecorderFull.Error() {
+		t.Errorf("dropped = %v, last error %q", stats.Dropped, stats.LastError)
+	}
+
+	lm.WriteLog(LevelError, "e1")
+	lm.WriteLog(LevelError, "e2") // buffer already flushed
+
+	entries, _ = lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "i1", "w1", "d2", "d3", "e1", "e2")
+}
+
+func TestFlightRecorderKeyed(t *testing.T) {
+	lm := newTestManager(t, Config{FlightRecorder: FlightRecorderConfig{
+		BufferBelow: LevelInfo,
+		Trigger:     LevelWarn,
+		KeyField:    "trace_id",
+		MaxKeys:     2,
+	}})
+	trace := func(id string) map[string]interface{} {
+		return map[string]interface{}{"trace_id": id}
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		lm.WriteLogWithMetadata(LevelDebug, fmt.Sprintf("%s debug", id), trace(id))
+	}
+	lm.WriteLog(LevelDebug, "untraced")
+
+	// "a" was evicted when "c" arrived, and "b" when the untraced buffer did
+	lm.WriteLogWithMetadata(LevelWarn, "b failed", trace("b"))
+	lm.WriteLogWithMetadata(LevelWarn, "c failed", trace("c"))
+	lm.WriteLog(LevelError, "untraced failed")
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "b failed", "c debug", "c failed", "untraced", "untraced failed")
+	if dropped := lm.Stats().Dropped; dropped[LevelDebug] != 2 {
+		t.Errorf("dropped = %v, want the entries of both evicted buffers", dropped)
+	}
+}
+
+func TestFlightRecorderInvalidConfig(t *testing.T) {
+	for _, config := range []FlightRecorderConfig{
+		{BufferBelow: "VERBOSE"},
+		{BufferBelow: LevelWarn, Trigger: LevelDebug},
+	} {
+		_, err := NewLogManager(Config{Backend: BackendMemory, BackendConfig: MemoryConfig{}, FlightRecorder: config})
+		if err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
+
+func TestFlightRecorderClose(t *testing.T) {
+	for _, flush := range []bool{false, true} {
+		lm := newTestManager(t, Config{FlightRecorder: FlightRecorderConfig{
+			BufferBelow:  LevelInfo,
+			KeyField:     "trace_id",
+			FlushOnClose: flush,
+		}})
+		lm.WriteLogWithMetadata(LevelDebug, "a1", map[string]interface{}{"trace_id": "a"})
+		lm.WriteLogWithMetadata(LevelDebug, "b1", map[string]interface{}{"trace_id": "b"})
+		lm.WriteLogWithMetadata(LevelDebug, "a2", map[string]interface{}{"trace_id": "a"})
+		lm.Close()
+
+		entries, _ := lm.ReadLogs("", LogFilter{})
+		stats := lm.Stats()
+		if flush {
+			expectMessages(t, entries, "a1", "b1", "a2")
+			if stats.Dropped[LevelDebug] != 0 {
+				t.Errorf("dropped = %v with FlushOnClose", stats.Dropped)
+			}
+		} else {
+			expectMessages(t, entries)
+			if stats.Dropped[LevelDebug] != 3 || stats.LastError != errFlightRecorderClosed.Error() {
+				t.Errorf("dropped = %v, last error %q", stats.Dropped, stats.LastError)
+			}
+		}
+		if stats.FlightRecorded != 0 {
+			t.Errorf("buffered = %d after Close", stats.FlightRecorded)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// /logger/handlers.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrHandlerTimeout is reported when a Handle call exceeds Config.HandlerTimeout
+	ErrHandlerTimeout = errors.New("log handler timed out")
+	// ErrHandlerQueueFull is reported when an entry is dropped for a slow handler
+	ErrHandlerQueueFull = errors.New("log handler queue is full, entry dropped")
+	// ErrHandlerDisabled is reported once when a handler is disabled; see
+	// EnableLogHandler
+	ErrHandlerDisabled = errors.New("log handler disabled after repeated failures")
+)
+
+// HandlerID identifies a registered LogHandler
+type HandlerID uint64
+
+// HandlerOption configures a handler registration
+type HandlerOption func(*handlerRegistration)
+
+// WithMinLevel only passes entries at least as severe as level to the handler
+func WithMinLevel(level LogLevel) HandlerOption {
+	return func(r *handlerRegistration) {
+		r.minLevel = level
+	}
+}
+
+// WithFilter only passes entries matching filter to the handler.
+// Paging fields of the filter are ignored.
+func WithFilter(filter LogFilter) HandlerOption {
+	return func(r *handlerRegistration) {
+		r.filter = &filter
+	}
+}
+
+// WithPriority orders dispatch; entries are queued to handlers with higher
+// priorities first, and to handlers of equal priority in registration order.
+// The default is 0.
+func WithPriority(priority int) HandlerOption {
+	return func(r *handlerRegistration) {
+		r.priority = priority
+	}
+}
+
+type handlerRegistration struct {
+	id       HandlerID
+	handler  LogHandler
+	priority int
+	minLevel LogLevel
+	filter   *LogFilter
+	matcher  *entryMatcher
+
+	// Isolated dispatch
+	queue    chan LogEntry
+	queueMu  sync.Mutex // guards sends against stop
+	stopped  bool
+	failures int // consecutive, owned by the runner goroutine
+	disabled atomic.Bool
+	pending  int // queued entries, guarded by logManagerImpl.pendingMu
+
+	// call is the sequence number of the running Handle call, 0 between
+	// calls; calls counts them and is owned by the runner goroutine
+	call  atomic.Uint64
+	calls uint64
+
+	// released is closed when Handle writes a FATAL entry, so that Close
+	// does not wait for the call
+	released    chan struct{}
+	releaseOnce sync.Once
+}
+
+// release stops the runner from waiting for the current Handle call
+func (r *handlerRegistration) release() {
+	r.releaseOnce.Do(func() { close(r.released) })
+}
+
+// accepts reports whether the entry passes the registration's level and filter
+func (r *handlerRegistration) accepts(entry LogEntry) bool {
+	if r.minLevel != "" {
+		sev, ok := entry.Level.Severity()
+		if min, _ := r.minLevel.Severity(); !ok || sev < min {
+			return false
+		}
+	}
+	return r.matcher == nil || r.matcher.match(entry)
+}
+
+func (lm *logManagerImpl) RegisterLogHandler(handler LogHandler, opts ...HandlerOption) (HandlerID, error) {
+	if handler == nil {
+		return 0, fmt.Errorf("nil log handler")
+	}
+
+	reg := &handlerRegistration{handler: handler, released: make(chan struct{})}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	if reg.minLevel != "" {
+		if _, ok := reg.minLevel.Severity(); !ok {
+			return 0, fmt.Errorf("unknown handler level: %s", reg.minLevel)
+		}
+	}
+	if reg.filter != nil {
+		m, err := newEntryMatcher("", *reg.filter)
+		if err != nil {
+			return 0, err
+		}
+		reg.matcher = m
+	}
+
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	select {
+	case <-lm.closed:
+		return 0, errors.New("log manager is closed")
+	default:
+	}
+
+	lm.nextHandlerID++
+	reg.id = lm.nextHandlerID
+	reg.queue = make(chan LogEntry, lm.handlerQueueSize())
+	lm.handlerWG.Add(1)
+	go lm.runHandler(reg)
+
+	// Copy on write so that snapshots taken by writers stay unchanged
+	handlers := make([]*handlerRegistration, len(lm.handlers), len(lm.handlers)+1)
+	copy(handlers, lm.handlers)
+	handlers = append(handlers, reg)
+	sort.SliceStable(handlers, func(i, j int) bool {
+		return handlers[i].priority > handlers[j].priority
+	})
+	lm.handlers = handlers
+
+	return reg.id, nil
+}
+
+// UnregisterLogHandler removes a handler; it reports whether id was registered
+func (lm *logManagerImpl) UnregisterLogHandler(id HandlerID) bool {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	for i, reg := range lm.handlers {
+		if reg.id == id {
+			handlers := make([]*handlerRegistration, 0, len(lm.handlers)-1)
+			handlers = append(handlers, lm.handlers[:i]...)
+			handlers = append(handlers, lm.handlers[i+1:]...)
+			lm.handlers = handlers
+			reg.stop()
+			return true
+		}
+	}
+	return false
+}
+
+// EnableLogHandler resumes a handler disabled after HandlerMaxFailures
+// consecutive failures; it reports whether id is registered
+func (lm *logManagerImpl) EnableLogHandler(id HandlerID) bool {
+	for _, reg := range lm.snapshotHandlers() {
+		if reg.id == id {
+			reg.disabled.Store(false)
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotHandlers returns the current handlers; the slice is never mutated
+func (lm *logManagerImpl) snapshotHandlers() []*handlerRegistration {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return lm.handlers
+}
+
+// notifyHandlers queues entry to every handler that accepts it, in priority order
+func (lm *logManagerImpl) notifyHandlers(entry LogEntry) {
+	for _, reg := range lm.snapshotHandlers() {
+		if reg.disabled.Load() || !reg.accepts(entry) {
+			continue
+		}
+		if !lm.enqueueHandler(reg, entry) {
+			lm.reportHandlerError(reg.id, ErrHandlerQueueFull)
+		}
+	}
+}
+
+// enqueueHandler queues entry without blocking; it reports false when the
+// queue is full. Entries for stopped handlers are discarded.
+func (lm *logManagerImpl) enqueueHandler(reg *handlerRegistration, entry LogEntry) bool {
+	reg.queueMu.Lock()
+	defer reg.queueMu.Unlock()
+
+	if reg.stopped {
+		return true
+	}
+
+	lm.pendingMu.Lock()
+	lm.pending++
+	reg.pending++
+	lm.pendingMu.Unlock()
+
+	select {
+	case reg.queue <- entry:
+		return true
+	default:
+		lm.handlerDone(reg)
+		return false
+	}
+}
+
+// stop closes the queue; the runner exits after draining it
+func (reg *handlerRegistration) stop() {
+	reg.queueMu.Lock()
+	defer reg.queueMu.Unlock()
+
+	if !reg.stopped {
+		reg.stopped = true
+		close(reg.queue)
+	}
+}
+
+// runHandler is the goroutine delivering queued entries to one handler.
+// Handle is never called concurrently: after a timeout the next entry waits
+// for the abandoned call to return.
+func (lm *logManagerImpl) runHandler(reg *handlerRegistration) {
+	defer lm.handlerWG.Done()
+
+	var abandoned <-chan error
+	for entry := range reg.queue {
+		if !reg.disabled.Load() {
+			if abandoned != nil {
+				select {
+				case <-abandoned:
+					abandoned = nil
+				case <-lm.closed:
+					// A handler that never returns must not block Close;
+					// the remaining entries are discarded
+					lm.handlerDone(reg)
+					for range reg.queue {
+						lm.handlerDone(reg)
+					}
+					return
+				}
+			}
+
+			var err error
+			abandoned, err = lm.callHandler(reg, entry)
+			if err != nil {
+				lm.reportHandlerError(reg.id, err)
+				reg.failures++
+				if reg.failures >= lm.handlerMaxFailures() {
+					reg.failures = 0
+					reg.disabled.Store(true)
+					lm.reportHandlerError(reg.id, ErrHandlerDisabled)
+				}
+			} else {
+				reg.failures = 0
+			}
+		}
+		lm.handlerDone(reg)
+	}
+}
+
+// callHandler runs Handle with panic recovery and the configured timeout.
+// A call that times out is abandoned; its result channel is returned so the
+// runner can wait for it before the next call.
+func (lm *logManagerImpl) callHandler(reg *handlerRegistration, entry LogEntry) (<-chan error, error) {
+	reg.calls++
+	call := reg.calls
+	reg.call.Store(call)
+
+	result := make(chan error, 1)
+	go func() {
+		defer reg.call.CompareAndSwap(call, 0)
+		defer func() {
+			if p := recover(); p != nil {
+				result <- fmt.Errorf("log handler panic: %v", p)
+			}
+		}()
+		result <- reg.handler.Handle(entry)
+	}()
+
+	timer := time.NewTimer(lm.handlerTimeout())
+	defer timer.Stop()
+
+	select {
+	case err := <-result:
+		return nil, err
+	case <-timer.C:
+		return result, ErrHandlerTimeout
+	case <-reg.released:
+		return result, nil
+	}
+}
+
+// handlerCalls records the Handle calls running when a FATAL or PANIC entry
+// is written, as the entry may come from one of them
+type handlerCalls map[*handlerRegistration]uint64
+
+// runningCalls returns the handlers that are in the middle of a call
+func (lm *logManagerImpl) runningCalls() handlerCalls {
+	var calls handlerCalls
+	for _, reg := range lm.snapshotHandlers() {
+		if call := reg.call.Load(); call != 0 {
+			if calls == nil {
+				calls = make(handlerCalls)
+			}
+			calls[reg] = call
+		}
+	}
+	return calls
+}
+
+// stillRunning reports whether reg is still in the recorded call
+func (c handlerCalls) stillRunning(reg *handlerRegistration) bool {
+	call, ok := c[reg]
+	return ok && reg.call.Load() == call
+}
+
+func (lm *logManagerImpl) reportHandlerError(id HandlerID, err error) {
+	lm.stats.handlerError(err)
+	if lm.config.OnHandlerError != nil {
+		lm.config.OnHandlerError(id, err)
+	}
+}
+
+// handlerDone marks one entry queued to reg as processed
+func (lm *logManagerImpl) handlerDone(reg *handlerRegistration) {
+	lm.pendingMu.Lock()
+	defer lm.pendingMu.Unlock()
+
+	lm.pending--
+	reg.pending--
+	lm.pendingCond.Broadcast()
+}
+
+// waitHandlersIdle blocks until every queued entry has been handled, except
+// entries of handlers still in one of the skipped calls
+func (lm *logManagerImpl) waitHandlersIdle(skip handlerCalls) {
+	lm.pendingMu.Lock()
+	defer lm.pendingMu.Unlock()
+
+	for {
+		n := lm.pending
+		for reg := range skip {
+			if skip.stillRunning(reg) {
+				n -= reg.pending
+			}
+		}
+		if n == 0 {
+			return
+		}
+		lm.pendingCond.Wait()
+	}
+}
+
+// stopHandlers stops every runner and waits for their queues to drain
+func (lm *logManagerImpl) stopHandlers() {
+	for _, reg := range lm.snapshotHandlers() {
+		reg.stop()
+	}
+	lm.handlerWG.Wait()
+}
+
+func (lm *logManagerImpl) handlerQueueSize() int {
+	if lm.config.HandlerQueueSize > 0 {
+		return lm.config.HandlerQueueSize
+	}
+	return defaultHandlerQueueSize
+}
+
+func (lm *logManagerImpl) handlerTimeout() time.Duration {
+	if lm.config.HandlerTimeout > 0 {
+		return lm.config.HandlerTimeout
+	}
+	return defaultHandlerTimeout
+}
+
+func (lm *logManagerImpl) handlerMaxFailures() int {
+	if lm.config.HandlerMaxFailures > 0 {
+		return lm.config.HandlerMaxFailures
+	}
+	return defaultHandlerMaxFailures
+}
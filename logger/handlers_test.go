@@ -0,0 +1,251 @@
+// /logger/handlers_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingHandler appends its name and the message to a shared log
+type recordingHandler struct {
+	name string
+	mu   *sync.Mutex
+	log  *[]string
+}
+
+func (h *recordingHandler) Handle(entry LogEntry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.log = append(*h.log, h.name+":"+entry.Message)
+	return nil
+}
+
+func TestHandlerOptions(t *testing.T) {
+	lm := newTestManager(t, Config{})
+
+	var mu sync.Mutex
+	calls := make(map[string]*[]string)
+	handler := func(name string) LogHandler {
+		calls[name] = &[]string{}
+		return &recordingHandler{name: name, mu: &mu, log: calls[name]}
+	}
+
+	lowID, _ := lm.RegisterLogHandler(handler("low"), WithPriority(-1))
+	errorsID, _ := lm.RegisterLogHandler(handler("errors"), WithMinLevel(LevelError), WithPriority(10))
+	diskID, _ := lm.RegisterLogHandler(handler("disk"), WithFilter(LogFilter{Contains: "disk"}))
+
+	lm.WriteLog(LevelInfo, "disk ok")
+	lm.WriteLog(LevelError, "disk failed")
+	lm.WriteLog(LevelError, "cpu hot")
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]string{
+		"low":    "[low:disk ok low:disk failed low:cpu hot]",
+		"errors": "[errors:disk failed errors:cpu hot]",
+		"disk":   "[disk:disk ok disk:disk failed]",
+	}
+	for name, w := range want {
+		if got := fmt.Sprint(*calls[name]); got != w {
+			t.Errorf("Handler %s got %s, want %s", name, got, w)
+		}
+	}
+
+	var order []HandlerID
+	for _, reg := range lm.(*logManagerImpl).snapshotHandlers() {
+		order = append(order, reg.id)
+	}
+	if fmt.Sprint(order) != fmt.Sprint([]HandlerID{errorsID, diskID, lowID}) {
+		t.Errorf("Unexpected dispatch order %v", order)
+	}
+}
+
+func TestUnregisterLogHandler(t *testing.T) {
+	lm := newTestManager(t, Config{})
+
+	handler := &TestLogHandler{}
+	id, err := lm.RegisterLogHandler(handler)
+	if err != nil {
+		t.Fatalf("RegisterLogHandler failed: %v", err)
+	}
+
+	lm.WriteLog(LevelInfo, "before")
+	if !lm.UnregisterLogHandler(id) {
+		t.Error("Expected handler to be unregistered")
+	}
+	if lm.UnregisterLogHandler(id) {
+		t.Error("Unregistering twice should report false")
+	}
+	lm.WriteLog(LevelInfo, "after")
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+
+	if len(handler.handledLogs) != 1 {
+		t.Errorf("Expected 1 handled log, got %d", len(handler.handledLogs))
+	}
+}
+
+func TestRegisterLogHandlerInvalidFilter(t *testing.T) {
+	lm := newTestManager(t, Config{})
+
+	if _, err := lm.RegisterLogHandler(&TestLogHandler{}, WithFilter(LogFilter{Regex: "("})); err == nil {
+		t.Error("Expected error for invalid filter")
+	}
+	if _, err := lm.RegisterLogHandler(&TestLogHandler{}, WithMinLevel("LOUD")); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+// flakyHandler fails, panics or blocks depending on the message
+type flakyHandler struct {
+	release    chan struct{}
+	active     atomic.Int32
+	overlapped atomic.Bool // set when Handle ran concurrently with itself
+}
+
+func (h *flakyHandler) Handle(entry LogEntry) error {
+	if h.active.Add(1) > 1 {
+		h.overlapped.Store(true)
+	}
+	defer h.active.Add(-1)
+
+	switch entry.Message {
+	case "panic":
+		panic("boom")
+	case "block":
+		<-h.release
+	case "fail":
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestHandlerIsolation(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+
+	lm, err := NewLogManager(Config{
+		Backend:            BackendFile,
+		BackendConfig:      FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")},
+		HandlerTimeout:     50 * time.Millisecond,
+		HandlerMaxFailures: 3,
+		OnHandlerError: func(id HandlerID, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	flaky := &flakyHandler{release: make(chan struct{})}
+	lm.RegisterLogHandler(flaky)
+
+	healthy := &TestLogHandler{}
+	lm.RegisterLogHandler(healthy)
+
+	start := time.Now()
+	for _, msg := range []string{"panic", "block", "ok", "fail", "fail", "fail", "ok"} {
+		if err := lm.WriteLog(LevelInfo, msg); err != nil {
+			t.Fatalf("WriteLog failed: %v", err)
+		}
+	}
+	if time.Since(start) > 40*time.Millisecond {
+		t.Error("WriteLog was blocked by a slow handler")
+	}
+
+	// The entries after "block" wait for the abandoned call
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		mu.Lock()
+		timedOut := len(reported) >= 2
+		mu.Unlock()
+		if timedOut {
+			break
+		}
+	}
+	close(flaky.release)
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+
+	if flaky.overlapped.Load() {
+		t.Error("Handle ran concurrently after a timeout")
+	}
+
+	if len(healthy.handledLogs) != 7 {
+		t.Errorf("Healthy handler should see all 7 entries, got %d", len(healthy.handledLogs))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var got []string
+	for _, err := range reported {
+		got = append(got, err.Error())
+	}
+	want := "[log handler panic: boom log handler timed out failed failed failed log handler disabled after repeated failures]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("Unexpected reported errors:\n got: %v\nwant: %s", got, want)
+	}
+}
+
+func TestEnableLogHandler(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+	lm := newTestManager(t, Config{
+		HandlerMaxFailures: 2,
+		OnHandlerError: func(id HandlerID, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	})
+
+	id, _ := lm.RegisterLogHandler(&flakyHandler{})
+	for _, msg := range []string{"fail", "fail", "fail"} {
+		lm.WriteLog(LevelInfo, msg)
+	}
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+
+	if !lm.EnableLogHandler(id) || lm.EnableLogHandler(id+1) {
+		t.Fatal("EnableLogHandler reported the wrong registrations")
+	}
+	lm.WriteLog(LevelInfo, "fail")
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The third entry is skipped while disabled, the fourth handled again
+	if want := "[failed failed log handler disabled after repeated failures failed]"; fmt.Sprint(reported) != want {
+		t.Errorf("reported = %v, want %s", reported, want)
+	}
+}
+
+func TestCloseWithStuckHandler(t *testing.T) {
+	lm, err := NewLogManager(Config{Backend: BackendMemory, BackendConfig: MemoryConfig{}, HandlerTimeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	flaky := &flakyHandler{release: make(chan struct{})}
+	defer close(flaky.release)
+	lm.RegisterLogHandler(flaky)
+
+	lm.WriteLog(LevelInfo, "block")
+	lm.WriteLog(LevelInfo, "ok")
+
+	done := make(chan struct{})
+	go func() {
+		lm.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked on a handler that never returns")
+	}
+}
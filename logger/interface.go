@@ -2,31 +2,74 @@
 
 package logger
 
-import "time"
+import (
+	"context"
+	"iter"
+	"time"
+)
 
 // LogLevel defines log severity levels
 type LogLevel string
 
+// Built-in levels; see RegisterLevel for custom ones
 const (
+	LevelTrace LogLevel = "TRACE"
 	LevelDebug LogLevel = "DEBUG"
 	LevelInfo  LogLevel = "INFO"
 	LevelWarn  LogLevel = "WARN"
 	LevelError LogLevel = "ERROR"
+	LevelFatal LogLevel = "FATAL" // flushes, closes the manager, then exits
+	LevelPanic LogLevel = "PANIC" // flushes, then panics with the message
 )
 
 // LogEntry represents a single log record
 type LogEntry struct {
-	Level     LogLevel
-	Message   string
-	Timestamp time.Time
-	Metadata  map[string]interface{}
+	Level     LogLevel               `json:"level"`
+	Message   string                 `json:"message"`
+	Timestamp time.Time              `json:"timestamp"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// SortOrder defines the order in which logs are returned
+type SortOrder string
+
+const (
+	OrderAsc  SortOrder = "asc"  // oldest first (default)
+	OrderDesc SortOrder = "desc" // newest first
+)
+
 // LogFilter provides filtering criteria for reading logs
 type LogFilter struct {
 	StartTime *time.Time
 	EndTime   *time.Time
 	Contains  string
+
+	// Levels restricts entries to the listed levels; MinLevel to levels at
+	// least as severe, which must be a known level. Both may be combined
+	// with the level argument of Read.
+	Levels   []LogLevel
+	MinLevel LogLevel
+
+	Regex      string              // regular expression the message must match
+	IgnoreCase bool                // case-insensitive Contains, Excludes and Regex
+	Excludes   []string            // drop entries whose message contains any of these
+	Metadata   []MetadataPredicate // all predicates must hold
+	Expr       QueryNode           // parsed query expression, see ParseFilter
+
+	// Paging
+	Limit  int       // maximum entries to return, 0 means no limit
+	Order  SortOrder // default: OrderAsc
+	Cursor string    // opaque cursor taken from a previous LogPage
+}
+
+// LogPage is a single page of results returned by ReadLogsPage
+type LogPage struct {
+	Entries []LogEntry
+
+	// NextCursor continues in the filter's order; empty when there is nothing more
+	NextCursor string
+	// PrevCursor pages back toward the first page; empty on the first page
+	PrevCursor string
 }
 
 // LogHandler allows extension (e.g., sending logs to external systems)
@@ -45,18 +88,47 @@ type LogBackend interface {
 	// Read retrieves logs based on filter
 	Read(level LogLevel, filter LogFilter) ([]LogEntry, error)
 
-	// ClearLogs removes logs older than specified time
+	// ClearLogs removes logs at or before the specified time
 	ClearLogs(before time.Time) error
 
 	// Close releases resources
 	Close() error
 }
 
+// PagedBackend is implemented by backends that support cursor-based paging.
+// Backends without it are paged in memory by the LogManager.
+type PagedBackend interface {
+	ReadPage(level LogLevel, filter LogFilter) (LogPage, error)
+}
+
+// StreamingBackend is implemented by backends that can read logs incrementally
+// without buffering the whole result. Resources are released as soon as the
+// iteration stops or ctx is cancelled.
+type StreamingBackend interface {
+	Stream(ctx context.Context, level LogLevel, filter LogFilter) iter.Seq2[LogEntry, error]
+}
+
 // LogManager is the core interface for managing logs
 type LogManager interface {
 	WriteLog(level LogLevel, message string) error
+	WriteLogWithMetadata(level LogLevel, message string, metadata map[string]interface{}) error
+	WriteLogFunc(level LogLevel, message func() string, metadata map[string]interface{}) error
+	Debugf(format string, args ...interface{}) error
+	Infof(format string, args ...interface{}) error
+	Warnf(format string, args ...interface{}) error
+	Errorf(format string, args ...interface{}) error
+	Enabled(level LogLevel) bool
 	ReadLogs(level LogLevel, filter LogFilter) ([]LogEntry, error)
+	ReadLogsPage(level LogLevel, filter LogFilter) (LogPage, error)
+	StreamLogs(ctx context.Context, level LogLevel, filter LogFilter) iter.Seq2[LogEntry, error]
+	Aggregate(ctx context.Context, level LogLevel, filter LogFilter, opts AggregateOptions) (AggregateResult, error)
 	ClearLogs(before time.Time) error
-	RegisterLogHandler(handler LogHandler)
+	RegisterLogHandler(handler LogHandler, opts ...HandlerOption) (HandlerID, error)
+	UnregisterLogHandler(id HandlerID) bool
+	EnableLogHandler(id HandlerID) bool
+	Subscribe(ctx context.Context, filter LogFilter) (<-chan LogEntry, error)
+	Tail(ctx context.Context, n int, filter LogFilter) (<-chan LogEntry, error)
+	Stats() LogStats
+	Health(ctx context.Context) HealthStatus
 	Close() error
 }
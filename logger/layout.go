@@ -0,0 +1,336 @@
+// /logger/layout.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layoutMissing is written for %{caller} and %{meta:key} when the key is absent
+const layoutMissing = "-"
+
+// levelShort are the three letter level names used by %{level:short}
+var levelShort = map[LogLevel]string{
+	LevelTrace: "TRC",
+	LevelDebug: "DBG",
+	LevelInfo:  "INF",
+	LevelWarn:  "WRN",
+	LevelError: "ERR",
+	LevelFatal: "FTL",
+	LevelPanic: "PNC",
+}
+
+// Patterns for values written by logfmtEncodeValue
+const (
+	layoutValuePattern  = `(?:"(?:[^"\\]|\\.)*"|[^\s"=\\]+)`
+	layoutFieldsPattern = `(?:[^\s="]+=` + layoutValuePattern + `(?: [^\s="]+=` + layoutValuePattern + `)*)?`
+)
+
+type layoutKind int
+
+const (
+	layoutLiteral layoutKind = iota
+	layoutTime
+	layoutLevel
+	layoutMessage
+	layoutMeta
+	layoutFields
+)
+
+type layoutPart struct {
+	kind layoutKind
+	text string // literal text, time layout, level style or metadata key
+}
+
+// layoutFormat renders lines from a template and parses them back with a
+// regular expression derived from the same template
+type layoutFormat struct {
+	parts    []layoutPart
+	shown    map[string]bool // metadata keys with their own placeholder
+	pattern  *regexp.Regexp
+	captures []layoutPart // the part behind each capture group
+}
+
+// NewLayoutFormat builds a line format from a template. Placeholders are:
+//
+//	%{time} or %{time:<Go layout>}   timestamp, RFC 3339 by default
+//	%{level}, %{level:pad}           level, padded to five characters
+//	%{level:short}, %{level:lower}   three letter or lowercase level
+//	%{message}                       message
+//	%{caller}                        the caller metadata key
+//	%{meta:<key>}                    a single metadata value, "-" when absent
+//	%{fields}                        remaining metadata as sorted key=value pairs
+//	%%                               a literal percent sign
+//
+// The template must contain %{time} and %{level} so entries can be filtered
+// when read back.
+func NewLayoutFormat(template string) (Format, error) {
+	parts, err := parseLayout(template)
+	if err != nil {
+		return Format{}, err
+	}
+
+	lf := &layoutFormat{parts: parts, shown: make(map[string]bool)}
+	var haveTime, haveLevel bool
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	for _, part := range parts {
+		switch part.kind {
+		case layoutLiteral:
+			pattern.WriteString(regexp.QuoteMeta(part.text))
+			continue
+		case layoutTime:
+			haveTime = true
+			pattern.WriteString(`(.+?)`)
+		case layoutLevel:
+			haveLevel = true
+			if part.text == "pad" {
+				pattern.WriteString(`(\S+) *`)
+			} else {
+				pattern.WriteString(`(\S+)`)
+			}
+		case layoutMessage:
+			pattern.WriteString(`(.*?)`)
+		case layoutMeta:
+			lf.shown[part.text] = true
+			pattern.WriteString(`(` + layoutValuePattern + `)`)
+		case layoutFields:
+			pattern.WriteString(`(` + layoutFieldsPattern + `)`)
+		}
+		lf.captures = append(lf.captures, part)
+	}
+	pattern.WriteString("$")
+
+	if !haveTime || !haveLevel {
+		return Format{}, errors.New("layout needs %{time} and %{level}")
+	}
+
+	lf.pattern, err = regexp.Compile(pattern.String())
+	if err != nil {
+		return Format{}, fmt.Errorf("failed to derive layout parser: %w", err)
+	}
+	return Format{Encoder: lf, Decoder: lf}, nil
+}
+
+// parseLayout splits a template into literals and placeholders
+func parseLayout(template string) ([]layoutPart, error) {
+	var parts []layoutPart
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, layoutPart{kind: layoutLiteral, text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for s := template; s != ""; {
+		i := strings.IndexByte(s, '%')
+		if i == -1 {
+			literal.WriteString(s)
+			break
+		}
+		literal.WriteString(s[:i])
+		s = s[i:]
+
+		if strings.HasPrefix(s, "%%") {
+			literal.WriteByte('%')
+			s = s[2:]
+			continue
+		}
+		if !strings.HasPrefix(s, "%{") {
+			return nil, fmt.Errorf("expected %%{ or %%%% at %q", s)
+		}
+		end := strings.IndexByte(s, '}')
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated placeholder %q", s)
+		}
+		name, arg, _ := strings.Cut(s[2:end], ":")
+		s = s[end+1:]
+
+		part, err := layoutPlaceholder(name, arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) > 0 && literal.Len() == 0 && parts[len(parts)-1].kind != layoutLiteral {
+			return nil, fmt.Errorf("placeholder %%{%s} must be separated from the previous one", name)
+		}
+		flush()
+		parts = append(parts, part)
+	}
+	flush()
+	return parts, nil
+}
+
+func layoutPlaceholder(name, arg string) (layoutPart, error) {
+	switch name {
+	case "time":
+		if arg == "" {
+			arg = time.RFC3339
+		}
+		return layoutPart{kind: layoutTime, text: arg}, nil
+	case "level":
+		switch arg {
+		case "", "pad", "short", "lower":
+			return layoutPart{kind: layoutLevel, text: arg}, nil
+		}
+		return layoutPart{}, fmt.Errorf("unknown level style: %s", arg)
+	case "message":
+		return layoutPart{kind: layoutMessage}, nil
+	case "caller":
+		return layoutPart{kind: layoutMeta, text: CallerKey}, nil
+	case "meta":
+		if arg == "" {
+			return layoutPart{}, errors.New("%{meta} needs a key")
+		}
+		return layoutPart{kind: layoutMeta, text: arg}, nil
+	case "fields":
+		return layoutPart{kind: layoutFields}, nil
+	}
+	return layoutPart{}, fmt.Errorf("unknown placeholder: %%{%s}", name)
+}
+
+func (lf *layoutFormat) Encode(entry LogEntry) ([]byte, error) {
+	var b strings.Builder
+	for _, part := range lf.parts {
+		switch part.kind {
+		case layoutLiteral:
+			b.WriteString(part.text)
+		case layoutTime:
+			b.WriteString(entry.Timestamp.Format(part.text))
+		case layoutLevel:
+			b.WriteString(formatLayoutLevel(entry.Level, part.text))
+		case layoutMessage:
+			b.WriteString(entry.Message)
+		case layoutMeta:
+			v, ok := entry.Metadata[part.text]
+			if !ok {
+				b.WriteString(layoutMissing)
+				continue
+			}
+			value, err := logfmtEncodeValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode metadata %s: %w", part.text, err)
+			}
+			b.WriteString(value)
+		case layoutFields:
+			if err := lf.encodeFields(&b, entry.Metadata); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+// encodeFields writes the metadata without a placeholder of its own
+func (lf *layoutFormat) encodeFields(b *strings.Builder, metadata map[string]interface{}) error {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		if !lf.shown[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if k == "" || strings.ContainsAny(k, " =\"\t\n") {
+			return fmt.Errorf("invalid metadata key: %q", k)
+		}
+		value, err := logfmtEncodeValue(metadata[k])
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata %s: %w", k, err)
+		}
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k + "=" + value)
+	}
+	return nil
+}
+
+func formatLayoutLevel(level LogLevel, style string) string {
+	switch style {
+	case "pad":
+		return fmt.Sprintf("%-5s", level)
+	case "short":
+		if short, ok := levelShort[level]; ok {
+			return short
+		}
+	case "lower":
+		return strings.ToLower(string(level))
+	}
+	return string(level)
+}
+
+func parseLayoutLevel(s, style string) LogLevel {
+	switch style {
+	case "short":
+		for level, short := range levelShort {
+			if short == s {
+				return level
+			}
+		}
+	case "lower":
+		return LogLevel(strings.ToUpper(s))
+	}
+	return LogLevel(s)
+}
+
+func (lf *layoutFormat) Decode(line []byte) (LogEntry, error) {
+	match := lf.pattern.FindStringSubmatch(string(line))
+	if match == nil {
+		return LogEntry{}, errors.New("line does not match layout")
+	}
+
+	var entry LogEntry
+	setMeta := func(key string, value interface{}) {
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]interface{})
+		}
+		entry.Metadata[key] = value
+	}
+
+	for i, part := range lf.captures {
+		raw := match[i+1]
+		switch part.kind {
+		case layoutTime:
+			ts, err := time.ParseInLocation(part.text, raw, time.Local)
+			if err != nil {
+				return LogEntry{}, fmt.Errorf("invalid timestamp: %w", err)
+			}
+			entry.Timestamp = ts
+		case layoutLevel:
+			entry.Level = parseLayoutLevel(raw, part.text)
+		case layoutMessage:
+			entry.Message = raw
+		case layoutMeta:
+			if raw == layoutMissing {
+				continue
+			}
+			quoted := strings.HasPrefix(raw, `"`)
+			if quoted {
+				v, err := strconv.Unquote(raw)
+				if err != nil {
+					return LogEntry{}, fmt.Errorf("invalid value for %s: %w", part.text, err)
+				}
+				raw = v
+			}
+			setMeta(part.text, logfmtDecodeValue(raw, quoted))
+		case layoutFields:
+			err := parseLogfmt(raw, func(key, value string, quoted bool) error {
+				setMeta(key, logfmtDecodeValue(value, quoted))
+				return nil
+			})
+			if err != nil {
+				return LogEntry{}, err
+			}
+		}
+	}
+	return entry, nil
+}
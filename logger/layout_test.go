@@ -0,0 +1,110 @@
+// /logger/layout_test.go
+
+package logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLayoutFormat(t *testing.T) {
+	format, err := NewLayoutFormat("%{time:2006-01-02 15:04:05.000} [%{level:pad}] (%{meta:component}) %{message} %{fields}")
+	if err != nil {
+		t.Fatalf("NewLayoutFormat failed: %v", err)
+	}
+
+	entry := LogEntry{
+		Level:     LevelInfo,
+		Message:   "job done [ok]",
+		Timestamp: time.Date(2025, 1, 1, 12, 0, 0, 123e6, time.Local),
+		Metadata:  map[string]interface{}{"component": "scheduler", "jobs": 3.0, "owner": "ops team"},
+	}
+	line, err := format.Encoder.Encode(entry)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	want := `2025-01-01 12:00:00.123 [INFO ] (scheduler) job done [ok] jobs=3 owner="ops team"`
+	if string(line) != want {
+		t.Errorf("line = %s, want %s", line, want)
+	}
+
+	got, err := format.Decoder.Decode(line)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !got.Timestamp.Equal(entry.Timestamp) || got.Level != entry.Level ||
+		got.Message != entry.Message || !reflect.DeepEqual(got.Metadata, entry.Metadata) {
+		t.Errorf("decoded %+v, want %+v", got, entry)
+	}
+}
+
+func TestLayoutLevelStyles(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		layout string
+		want   string
+	}{
+		{"%{time} %{level:short} %{message}", "2025-01-01T12:00:00Z WRN careful"},
+		{"%{time} %{level:lower} %{caller} %{message}", "2025-01-01T12:00:00Z warn - careful"},
+		{"%{time} %{level} 100%% %{message}", "2025-01-01T12:00:00Z WARN 100% careful"},
+	} {
+		format, err := NewLayoutFormat(tc.layout)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.layout, err)
+		}
+		line, _ := format.Encoder.Encode(LogEntry{Level: LevelWarn, Message: "careful", Timestamp: ts})
+		if string(line) != tc.want {
+			t.Errorf("%s: line = %s, want %s", tc.layout, line, tc.want)
+		}
+		entry, err := format.Decoder.Decode(line)
+		if err != nil || entry.Level != LevelWarn || entry.Message != "careful" || entry.Metadata != nil {
+			t.Errorf("%s: decoded %+v, %v", tc.layout, entry, err)
+		}
+	}
+}
+
+func TestLayoutInvalid(t *testing.T) {
+	for _, layout := range []string{
+		"%{level} %{message}",
+		"%{time} %{message}",
+		"%{time} %{level:tiny}",
+		"%{time} %{level} %{meta}",
+		"%{time} %{level} %{color}",
+		"%{time} %{level} %{message",
+		"%{time} %{level} 50%",
+		"%{time} %{level}%{message}",
+	} {
+		if _, err := NewLayoutFormat(layout); err == nil {
+			t.Errorf("expected error for %q", layout)
+		}
+	}
+}
+
+func TestFileBackendLayout(t *testing.T) {
+	fb := &FileBackend{}
+	err := fb.Init(FileConfig{
+		FilePath: t.TempDir() + "/app.log",
+		Layout:   "%{time:2006-01-02 15:04:05.000} [%{level}] %{message} %{fields}",
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer fb.Close()
+
+	fb.Write(LogEntry{Level: LevelInfo, Message: "hello world", Timestamp: time.Now()})
+	fb.Write(LogEntry{Level: LevelError, Message: "failed", Timestamp: time.Now(),
+		Metadata: map[string]interface{}{"user": "alice"}})
+
+	entries, err := fb.Read(LevelError, LogFilter{})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Message != "failed" || entries[0].Metadata["user"] != "alice" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	if err := (&FileBackend{}).Init(FileConfig{FilePath: t.TempDir() + "/app.log", Format: FormatJSON, Layout: "%{time} %{level}"}); err == nil {
+		t.Error("expected error combining a layout with the JSON format")
+	}
+}
@@ -0,0 +1,111 @@
+// /logger/lazy.go
+
+package logger
+
+import "fmt"
+
+// LogValuer is implemented by values that are expensive to compute. As a
+// metadata value or a Printf style argument, LogValue is only called when
+// the entry's level is enabled.
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// maxLogValueDepth bounds LogValuers returning LogValuers
+const maxLogValueDepth = 10
+
+// logMessage is a message built on demand: text as is, text as a format
+// applied to args, or the result of fn
+type logMessage struct {
+	text   string
+	args   []interface{}
+	printf bool
+	fn     func() string
+}
+
+func (m logMessage) String() string {
+	switch {
+	case m.fn != nil:
+		return m.fn()
+	case m.printf:
+		return fmt.Sprintf(m.text, resolveArgs(m.args)...)
+	}
+	return m.text
+}
+
+// resolveValue calls LogValue until the result is not a LogValuer
+func resolveValue(v interface{}) interface{} {
+	for i := 0; i < maxLogValueDepth; i++ {
+		lv, ok := v.(LogValuer)
+		if !ok {
+			return v
+		}
+		v = lv.LogValue()
+	}
+	return v
+}
+
+// resolveArgs returns args with LogValuers resolved, copying only if needed
+func resolveArgs(args []interface{}) []interface{} {
+	var out []interface{}
+	for i, arg := range args {
+		if _, ok := arg.(LogValuer); !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}(nil), args...)
+		}
+		out[i] = resolveValue(arg)
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// resolveMetadata returns metadata with LogValuers resolved. The caller's
+// map is copied rather than modified.
+func resolveMetadata(metadata map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	for k, v := range metadata {
+		if _, ok := v.(LogValuer); !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(metadata))
+			for k, v := range metadata {
+				out[k] = v
+			}
+		}
+		out[k] = resolveValue(v)
+	}
+	if out == nil {
+		return metadata
+	}
+	return out
+}
+
+func (lm *logManagerImpl) Debugf(format string, args ...interface{}) error {
+	return lm.write(LevelDebug, logMessage{text: format, args: args, printf: true}, nil)
+}
+
+func (lm *logManagerImpl) Infof(format string, args ...interface{}) error {
+	return lm.write(LevelInfo, logMessage{text: format, args: args, printf: true}, nil)
+}
+
+func (lm *logManagerImpl) Warnf(format string, args ...interface{}) error {
+	return lm.write(LevelWarn, logMessage{text: format, args: args, printf: true}, nil)
+}
+
+func (lm *logManagerImpl) Errorf(format string, args ...interface{}) error {
+	return lm.write(LevelError, logMessage{text: format, args: args, printf: true}, nil)
+}
+
+// WriteLogFunc writes an entry whose message is built by message, which is
+// only called when level is enabled
+func (lm *logManagerImpl) WriteLogFunc(level LogLevel, message func() string, metadata map[string]interface{}) error {
+	if message == nil {
+		return fmt.Errorf("nil message func")
+	}
+	return lm.write(level, logMessage{fn: message}, metadata)
+}
@@ -0,0 +1,85 @@
+// /logger/lazy_test.go
+
+package logger
+
+import (
+	"fmt"
+	"testing"
+)
+
+// countingValuer counts how often it is evaluated
+type countingValuer struct {
+	calls int
+	value interface{}
+}
+
+func (v *countingValuer) LogValue() interface{} {
+	v.calls++
+	return v.value
+}
+
+func TestPrintfMethods(t *testing.T) {
+	lm := newTestManager(t, Config{CaptureCaller: true})
+
+	line := currentLine() + 1
+	lm.Debugf("loaded %d plugins", 3)
+	lm.Infof("listening on %s", ":8080")
+	lm.Warnf("disk at %.0f%%", 91.5)
+	lm.Errorf("100%% failed")
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "loaded 3 plugins", "listening on :8080", "disk at 92%", "100% failed")
+	for i, level := range []LogLevel{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if entries[i].Level != level {
+			t.Errorf("entry %d level = %s, want %s", i, entries[i].Level, level)
+		}
+	}
+	if want := fmt.Sprintf("logger/lazy_test.go:%d", line); entries[0].Metadata[CallerKey] != want {
+		t.Errorf("caller = %v, want %s", entries[0].Metadata[CallerKey], want)
+	}
+}
+
+func TestLazyEvaluation(t *testing.T) {
+	lm := newTestManager(t, Config{MinLevel: LevelInfo})
+
+	calls := 0
+	message := func() string {
+		calls++
+		return "expensive"
+	}
+	arg := &countingValuer{value: "summary"}
+	meta := &countingValuer{value: 42}
+	metadata := map[string]interface{}{"report": meta}
+
+	lm.WriteLogFunc(LevelDebug, message, metadata)
+	lm.Debugf("state: %v", arg)
+	if calls != 0 || arg.calls != 0 || meta.calls != 0 {
+		t.Fatalf("disabled entries were evaluated: %d, %d, %d", calls, arg.calls, meta.calls)
+	}
+
+	lm.WriteLogFunc(LevelInfo, message, metadata)
+	lm.Infof("state: %v", arg)
+	if calls != 1 || arg.calls != 1 || meta.calls != 1 {
+		t.Fatalf("enabled entries evaluated %d, %d, %d times", calls, arg.calls, meta.calls)
+	}
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "expensive", "state: summary")
+	if entries[0].Metadata["report"] != 42 || metadata["report"] != meta {
+		t.Errorf("metadata = %v, caller's map = %v", entries[0].Metadata, metadata)
+	}
+
+	if err := lm.WriteLogFunc(LevelInfo, nil, nil); err == nil {
+		t.Error("expected error for a nil message func")
+	}
+}
+
+func BenchmarkDebugfDisabled(b *testing.B) {
+	lm, _ := NewLogManager(Config{Backend: BackendMemory, BackendConfig: MemoryConfig{}, MinLevel: LevelInfo})
+	defer lm.Close()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lm.Debugf("request %d took %s", i, "1ms")
+	}
+}
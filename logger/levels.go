@@ -0,0 +1,199 @@
+// /logger/levels.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	levelsMu sync.RWMutex
+	// severities orders the known levels; unknown levels have no severity
+	severities = map[LogLevel]int{
+		LevelTrace: 5,
+		LevelDebug: 10,
+		LevelInfo:  20,
+		LevelWarn:  30,
+		LevelError: 40,
+		LevelFatal: 50,
+		LevelPanic: 60,
+	}
+)
+
+// RegisterLevel adds a custom level. Names are upper case so ParseLevel can
+// match them case-insensitively; severity places the level among the
+// built-in ones, e.g. 25 for a NOTICE between INFO and WARN.
+func RegisterLevel(level LogLevel, severity int) error {
+	name := string(level)
+	if name == "" || name != strings.ToUpper(name) || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid level name: %q", name)
+	}
+	if severity <= 0 {
+		return errors.New("level severity must be positive")
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if _, ok := severities[level]; ok {
+		return fmt.Errorf("level already registered: %s", level)
+	}
+	severities[level] = severity
+	return nil
+}
+
+// Severity returns the numeric severity of a built-in or registered level
+func (l LogLevel) Severity() (int, bool) {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+
+	sev, ok := severities[l]
+	return sev, ok
+}
+
+// Levels lists the known levels from least to most severe
+func Levels() []LogLevel {
+	levelsMu.RLock()
+	levels := make([]LogLevel, 0, len(severities))
+	for l := range severities {
+		levels = append(levels, l)
+	}
+	levelsMu.RUnlock()
+
+	sort.Slice(levels, func(i, j int) bool {
+		si, _ := levels[i].Severity()
+		sj, _ := levels[j].Severity()
+		if si != sj {
+			return si < sj
+		}
+		return levels[i] < levels[j]
+	})
+	return levels
+}
+
+// ParseLevel returns the known level named s, ignoring case
+func ParseLevel(s string) (LogLevel, error) {
+	level := LogLevel(strings.ToUpper(strings.TrimSpace(s)))
+	if _, ok := level.Severity(); !ok {
+		return "", fmt.Errorf("unknown level: %q", s)
+	}
+	return level, nil
+}
+
+// levelsAtLeast returns the known levels at least as severe as min
+func levelsAtLeast(min LogLevel) []LogLevel {
+	floor, ok := min.Severity()
+	if !ok {
+		return nil
+	}
+	var levels []LogLevel
+	for _, l := range Levels() {
+		if sev, _ := l.Severity(); sev >= floor {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
+
+// SyslogSeverity maps a level to an RFC 5424 severity. Custom levels map by
+// their severity; unknown levels are notice.
+func SyslogSeverity(level LogLevel) int {
+	sev, ok := level.Severity()
+	switch {
+	case !ok:
+		return 5
+	case sev >= 60:
+		return 1 // alert
+	case sev >= 50:
+		return 2 // critical
+	case sev >= 40:
+		return 3 // error
+	case sev >= 30:
+		return 4 // warning
+	case sev > 20:
+		return 5 // notice
+	case sev == 20:
+		return 6 // informational
+	}
+	return 7 // debug
+}
+
+// LevelFromSyslog maps an RFC 5424 severity to a built-in level
+func LevelFromSyslog(severity int) (LogLevel, bool) {
+	switch severity {
+	case 0, 1:
+		return LevelPanic, true
+	case 2:
+		return LevelFatal, true
+	case 3:
+		return LevelError, true
+	case 4:
+		return LevelWarn, true
+	case 5, 6:
+		return LevelInfo, true
+	case 7:
+		return LevelDebug, true
+	}
+	return "", false
+}
+
+// otelSeverities are the OpenTelemetry severity numbers of the built-in
+// levels; PANIC uses the upper half of the FATAL range
+var otelSeverities = map[LogLevel]int{
+	LevelTrace: 1,
+	LevelDebug: 5,
+	LevelInfo:  9,
+	LevelWarn:  13,
+	LevelError: 17,
+	LevelFatal: 21,
+	LevelPanic: 23,
+}
+
+// OTelSeverity maps a level to an OpenTelemetry severity number (1-24).
+// Custom levels fall into the range of the built-in level below them, e.g.
+// a NOTICE at 25 becomes INFO3 (11), so LevelFromOTel maps them back to
+// that level. Unknown levels are 0, unspecified.
+func OTelSeverity(level LogLevel) int {
+	if n, ok := otelSeverities[level]; ok {
+		return n
+	}
+	sev, ok := level.Severity()
+	if !ok {
+		return 0
+	}
+	return otelFromSeverity(sev)
+}
+
+// otelFromSeverity buckets a custom severity into the OpenTelemetry range
+// of the built-in level below it. FATAL and PANIC share the FATAL range,
+// 21-22 and 23-24.
+func otelFromSeverity(sev int) int {
+	switch {
+	case sev > 60:
+		return 24
+	case sev == 60:
+		return 23
+	case sev >= 50:
+		return 21 + (sev%10)/5
+	case sev < 10:
+		return 1
+	}
+	return 1 + 4*(sev/10) + (sev%10)*4/10
+}
+
+// LevelFromOTel maps an OpenTelemetry severity number to a built-in level
+func LevelFromOTel(severity int) (LogLevel, bool) {
+	switch {
+	case severity < 1 || severity > 24:
+		return "", false
+	case severity >= 23:
+		return LevelPanic, true
+	case severity >= 21:
+		return LevelFatal, true
+	}
+	return []LogLevel{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError}[(severity-1)/4], true
+}
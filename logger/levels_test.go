@@ -0,0 +1,250 @@
+// /logger/levels_test.go
+
+package logger
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+const levelNotice LogLevel = "NOTICE"
+
+// registerNotice adds a custom level between INFO and WARN once per process
+func registerNotice(t *testing.T) {
+	t.Helper()
+	if _, ok := levelNotice.Severity(); ok {
+		return
+	}
+	if err := RegisterLevel(levelNotice, 25); err != nil {
+		t.Fatalf("RegisterLevel failed: %v", err)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	registerNotice(t)
+
+	for _, tc := range []struct {
+		level    LogLevel
+		severity int
+	}{
+		{LevelInfo, 20},
+		{"lower", 20},
+		{"TWO WORDS", 20},
+		{"ZERO", 0},
+	} {
+		if err := RegisterLevel(tc.level, tc.severity); err == nil {
+			t.Errorf("expected error registering %q at %d", tc.level, tc.severity)
+		}
+	}
+
+	levels := levelsAtLeast(LevelInfo)
+	want := []LogLevel{LevelInfo, levelNotice, LevelWarn, LevelError, LevelFatal, LevelPanic}
+	if len(levels) < len(want) {
+		t.Fatalf("levelsAtLeast(INFO) = %v", levels)
+	}
+	for i, l := range want {
+		if levels[i] != l {
+			t.Errorf("levelsAtLeast(INFO) = %v, want %v first", levels, want)
+			break
+		}
+	}
+
+	if level, err := ParseLevel(" notice "); err != nil || level != levelNotice {
+		t.Errorf("ParseLevel = %q, %v", level, err)
+	}
+	if _, err := ParseLevel("FOO"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestCustomLevelFiltering(t *testing.T) {
+	registerNotice(t)
+	lm := newTestManager(t, Config{MinLevel: levelNotice})
+
+	lm.WriteLog(LevelInfo, "info")
+	lm.WriteLog(levelNotice, "notice")
+	lm.WriteLog(LevelTrace, "trace")
+	lm.WriteLog(LevelError, "error")
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "notice", "error")
+
+	filter, err := ParseFilter("level>NOTICE")
+	if err != nil {
+		t.Fatalf("ParseFilter failed: %v", err)
+	}
+	entries, _ = lm.ReadLogs("", filter)
+	expectMessages(t, entries, "error")
+}
+
+func TestSeverityMappings(t *testing.T) {
+	registerNotice(t)
+
+	for _, tc := range []struct {
+		level  LogLevel
+		syslog int
+		otel   int
+	}{
+		{LevelTrace, 7, 1},
+		{LevelDebug, 7, 5},
+		{LevelInfo, 6, 9},
+		{levelNotice, 5, 11},
+		{LevelWarn, 4, 13},
+		{LevelError, 3, 17},
+		{LevelFatal, 2, 21},
+		{LevelPanic, 1, 23},
+		{"FOO", 5, 0},
+	} {
+		if got := SyslogSeverity(tc.level); got != tc.syslog {
+			t.Errorf("SyslogSeverity(%s) = %d, want %d", tc.level, got, tc.syslog)
+		}
+		if got := OTelSeverity(tc.level); got != tc.otel {
+			t.Errorf("OTelSeverity(%s) = %d, want %d", tc.level, got, tc.otel)
+		}
+	}
+
+	for severity, want := range map[int]LogLevel{0: LevelPanic, 2: LevelFatal, 3: LevelError, 5: LevelInfo, 7: LevelDebug} {
+		if got, ok := LevelFromSyslog(severity); !ok || got != want {
+			t.Errorf("LevelFromSyslog(%d) = %s, want %s", severity, got, want)
+		}
+	}
+	for severity, want := range map[int]LogLevel{1: LevelTrace, 8: LevelDebug, 12: LevelInfo, 16: LevelWarn, 17: LevelError, 22: LevelFatal, 24: LevelPanic} {
+		if got, ok := LevelFromOTel(severity); !ok || got != want {
+			t.Errorf("LevelFromOTel(%d) = %s, want %s", severity, got, want)
+		}
+	}
+	if _, ok := LevelFromOTel(0); ok {
+		t.Error("expected no level for an unspecified OpenTelemetry severity")
+	}
+}
+
+func TestOTelSeverityRoundTrip(t *testing.T) {
+	builtin := []LogLevel{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic}
+	for sev := 1; sev <= 80; sev++ {
+		// The most severe built-in level at or below sev, TRACE below it
+		want := LevelTrace
+		for _, l := range builtin {
+			if s, _ := l.Severity(); s <= sev {
+				want = l
+			}
+		}
+		n := otelFromSeverity(sev)
+		if got, ok := LevelFromOTel(n); !ok || got != want {
+			t.Errorf("severity %d maps to OpenTelemetry %d, which reads back as %s, want %s", sev, n, got, want)
+		}
+	}
+}
+
+func TestFatalFlushesAndExits(t *testing.T) {
+	var code int
+	lm, err := NewLogManager(Config{
+		Backend:       BackendMemory,
+		BackendConfig: MemoryConfig{},
+		Async:         true,
+		Exit:          func(c int) { code = c },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	var mu sync.Mutex
+	var handled []string
+	lm.RegisterLogHandler(&recordingHandler{name: "h", mu: &mu, log: &handled})
+
+	lm.WriteLog(LevelInfo, "starting")
+	lm.WriteLog(LevelFatal, "config missing")
+
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if len(handled) != 2 || handled[1] != "h:config missing" {
+		t.Errorf("handled before exit: %v", handled)
+	}
+	if h := lm.Health(t.Context()); h.Healthy {
+		t.Error("expected the manager to be closed before exiting")
+	}
+}
+
+func TestPanicFlushesAndPanics(t *testing.T) {
+	lm := newTestManager(t, Config{Async: true})
+	var mu sync.Mutex
+	var handled []string
+	lm.RegisterLogHandler(&recordingHandler{name: "h", mu: &mu, log: &handled})
+
+	func() {
+		defer func() {
+			if r := recover(); r != "invariant broken" {
+				t.Errorf("recovered %v", r)
+			}
+		}()
+		lm.WriteLog(LevelPanic, "invariant broken")
+	}()
+
+	mu.Lock()
+	if len(handled) != 1 {
+		t.Errorf("handled before panic: %v", handled)
+	}
+	mu.Unlock()
+
+	// The manager keeps working after a recovered panic
+	lm.WriteLog(LevelInfo, "recovered")
+	lm.(*logManagerImpl).flush()
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "invariant broken", "recovered")
+}
+
+// reentrantHandler writes an entry at level when it sees "trigger"
+type reentrantHandler struct {
+	lm    LogManager
+	level LogLevel
+}
+
+func (h *reentrantHandler) Handle(entry LogEntry) error {
+	if entry.Message == "trigger" {
+		h.lm.WriteLog(h.level, "from handler")
+	}
+	return nil
+}
+
+func TestFatalFromHandler(t *testing.T) {
+	exited := make(chan int, 1)
+	lm := newTestManager(t, Config{Exit: func(c int) { exited <- c }})
+	lm.RegisterLogHandler(&reentrantHandler{lm: lm, level: LevelFatal})
+
+	lm.WriteLog(LevelInfo, "trigger")
+
+	// Well below the default HandlerTimeout that Close would otherwise wait for
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("exit code = %d, want 1", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("FATAL from a handler did not exit")
+	}
+	if h := lm.Health(t.Context()); h.Healthy {
+		t.Error("expected the manager to be closed before exiting")
+	}
+}
+
+func TestPanicFromHandler(t *testing.T) {
+	reported := make(chan error, 1)
+	lm := newTestManager(t, Config{OnHandlerError: func(id HandlerID, err error) {
+		reported <- err
+	}})
+	lm.RegisterLogHandler(&reentrantHandler{lm: lm, level: LevelPanic})
+
+	lm.WriteLog(LevelInfo, "trigger")
+
+	select {
+	case err := <-reported:
+		if err.Error() != "log handler panic: from handler" {
+			t.Errorf("reported %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PANIC from a handler was not reported")
+	}
+	lm.(*logManagerImpl).flush()
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	expectMessages(t, entries, "trigger", "from handler")
+}
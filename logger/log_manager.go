@@ -3,8 +3,11 @@
 package logger
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"iter"
+	"os"
 	"sync"
 	"time"
 )
@@ -13,20 +16,80 @@ type logManagerImpl struct {
 	mu       sync.Mutex
 	config   Config
 	backend  LogBackend
-	handlers []LogHandler
+	handlers []*handlerRegistration // sorted by priority, replaced on change
+
+	nextHandlerID HandlerID
+	handlerWG     sync.WaitGroup
+
+	// Entries queued to handlers but not yet handled
+	pendingMu   sync.Mutex
+	pendingCond *sync.Cond
+	pending     int
 
 	// Async support
-	logChannel chan LogEntry
-	done       chan struct{}
-	wg         sync.WaitGroup
-	isAsync    bool
+	logChannel    chan LogEntry
+	done          chan struct{}
+	flushRequests chan chan struct{} // closes the sent channel once drained
+	wg            sync.WaitGroup
+	isAsync       bool
+
+	// Write resilience
+	breaker *circuitBreaker
+	parkMu  sync.Mutex
+	parked  []LogEntry
+
+	// Live subscriptions
+	writeMu     sync.RWMutex
+	subMu       sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      chan struct{}
+	closeOnce   sync.Once
+
+	stats    *managerStats
+	recorder *flightRecorder
+	minAt    int // Config.MinLevel severity, 0 when disabled
+	stackAt  int // severity from which stacks are captured, 0 when disabled
 }
 
 // NewLogManager creates a new LogManager with the given configuration
 func NewLogManager(config Config) (LogManager, error) {
+	var minAt, stackAt int
+	if config.MinLevel != "" {
+		sev, ok := config.MinLevel.Severity()
+		if !ok {
+			return nil, fmt.Errorf("unknown minimum level: %s", config.MinLevel)
+		}
+		minAt = sev
+	}
+	if !config.DisableStackTraces {
+		if config.StackTraceLevel == "" {
+			config.StackTraceLevel = LevelError
+		}
+		sev, ok := config.StackTraceLevel.Severity()
+		if !ok {
+			return nil, fmt.Errorf("unknown stack trace level: %s", config.StackTraceLevel)
+		}
+		stackAt = sev
+	}
+
 	lm := &logManagerImpl{
 		config:  config,
 		isAsync: config.Async,
+		closed:  make(chan struct{}),
+		stats:   newManagerStats(),
+		minAt:   minAt,
+		stackAt: stackAt,
+	}
+	lm.pendingCond = sync.NewCond(&lm.pendingMu)
+	if config.CircuitBreaker.FailureThreshold > 0 {
+		lm.breaker = newCircuitBreaker(config.CircuitBreaker, config.OnBreakerStateChange)
+	}
+	if config.FlightRecorder.BufferBelow != "" {
+		recorder, err := newFlightRecorder(config.FlightRecorder)
+		if err != nil {
+			return nil, err
+		}
+		lm.recorder = recorder
 	}
 
 	// Create backend based on type
@@ -38,6 +101,12 @@ func NewLogManager(config Config) (LogManager, error) {
 		backend = &FileBackend{}
 	case BackendSQL:
 		backend = &SQLBackend{}
+	case BackendSyslog:
+		backend = &SyslogBackend{}
+	case BackendMemory:
+		backend = &MemoryBackend{}
+	case BackendConsole:
+		backend = &ConsoleBackend{}
 	default:
 		return nil, fmt.Errorf("unsupported backend type: %s", config.Backend)
 	}
@@ -53,6 +122,7 @@ func NewLogManager(config Config) (LogManager, error) {
 	if lm.isAsync {
 		lm.logChannel = make(chan LogEntry, 1000) // Buffer size of 1000
 		lm.done = make(chan struct{})
+		lm.flushRequests = make(chan chan struct{})
 		lm.startAsyncWorker()
 	}
 
@@ -67,81 +137,217 @@ func (lm *logManagerImpl) startAsyncWorker() {
 		for {
 			select {
 			case entry := <-lm.logChannel:
-				// Write to backend
-				if err := lm.backend.Write(entry); err != nil {
+				// Write to backend and notify handlers
+				if err := lm.process(entry); err != nil {
 					// In production, you might want to handle this error better
 					// For now, we'll just continue to avoid blocking
 					fmt.Printf("async log write error: %v\n", err)
 				}
 
-				// Notify handlers
-				lm.mu.Lock()
-				handlers := make([]LogHandler, len(lm.handlers))
-				copy(handlers, lm.handlers)
-				lm.mu.Unlock()
-
-				for _, h := range handlers {
-					_ = h.Handle(entry)
-				}
+			case done := <-lm.flushRequests:
+				lm.drain()
+				close(done)
 
 			case <-lm.done:
 				// Drain remaining logs before exiting
-				for {
-					select {
-					case entry := <-lm.logChannel:
-						_ = lm.backend.Write(entry)
-
-						lm.mu.Lock()
-						handlers := make([]LogHandler, len(lm.handlers))
-						copy(handlers, lm.handlers)
-						lm.mu.Unlock()
-
-						for _, h := range handlers {
-							_ = h.Handle(entry)
-						}
-					default:
-						return
-					}
-				}
+				lm.drain()
+				return
 			}
 		}
 	}()
 }
 
+// drain processes the entries already queued
+func (lm *logManagerImpl) drain() {
+	for {
+		select {
+		case entry := <-lm.logChannel:
+			_ = lm.process(entry)
+		default:
+			return
+		}
+	}
+}
+
+// flush waits until the entries written so far reached the backend and
+// every handler
+func (lm *logManagerImpl) flush() {
+	lm.flushExcept(nil)
+}
+
+// flushExcept is flush without waiting for handlers still in one of calls
+func (lm *logManagerImpl) flushExcept(calls handlerCalls) {
+	if lm.isAsync {
+		done := make(chan struct{})
+		select {
+		case lm.flushRequests <- done:
+			<-done
+		case <-lm.closed:
+		}
+	}
+	lm.waitHandlersIdle(calls)
+}
+
+// fatal closes the manager, which flushes everything, then exits. Close
+// waits for the handler runners, so handlers still in a call that was
+// running when the entry was written are released first; the FATAL entry
+// may come from that call.
+func (lm *logManagerImpl) fatal(calls handlerCalls) {
+	for reg := range calls {
+		if calls.stillRunning(reg) {
+			reg.release()
+		}
+	}
+	lm.Close()
+	exit := lm.config.Exit
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(1)
+}
+
+// panic flushes, then panics with the message of the PANIC entry. The flush
+// does not wait for handlers still in a call that was running when the entry
+// was written, which may be the caller itself; such a panic is recovered
+// and reported like any handler panic.
+func (lm *logManagerImpl) panic(message string, calls handlerCalls) {
+	lm.flushExcept(calls)
+	panic(message)
+}
+
+// closeRecorder writes or drops the entries left in the flight recorder
+func (lm *logManagerImpl) closeRecorder() {
+	if lm.recorder == nil {
+		return
+	}
+	for _, e := range lm.recorder.drain() {
+		if !lm.config.FlightRecorder.FlushOnClose {
+			lm.stats.drop(e.Level, errFlightRecorderClosed)
+			continue
+		}
+		lm.writeAndPublish(e) // failures are counted in Stats
+		lm.notifyHandlers(e)
+	}
+}
+
+// process sends entry to the backend, subscribers and handlers, or holds it
+// in the flight recorder. A trigger entry is preceded by its buffered entries.
+func (lm *logManagerImpl) process(entry LogEntry) error {
+	if lm.recorder != nil {
+		buffered, flush, dropped := lm.recorder.record(entry)
+		for _, e := range dropped {
+			lm.stats.drop(e.Level, errFlightRecorderFull)
+		}
+		if buffered {
+			return nil
+		}
+		for _, e := range flush {
+			// Failures are counted in Stats; the trigger entry is written regardless
+			lm.writeAndPublish(e)
+			lm.notifyHandlers(e)
+		}
+	}
+
+	if err := lm.writeAndPublish(entry); err != nil {
+		// Sync callers get the error instead; async entries are still handled
+		if !lm.isAsync {
+			return err
+		}
+		lm.notifyHandlers(entry)
+		return err
+	}
+	lm.notifyHandlers(entry)
+	return nil
+}
+
+// writeAndPublish writes entry to the backend and delivers it to subscribers.
+// Tail holds writeMu exclusively to mark the switch from history to live
+// entries.
+func (lm *logManagerImpl) writeAndPublish(entry LogEntry) error {
+	lm.writeMu.RLock()
+	defer lm.writeMu.RUnlock()
+
+	err := lm.writeBackend(entry)
+	if err != nil {
+		lm.stats.drop(entry.Level, err)
+	}
+	lm.publish(entry)
+	return err
+}
+
 func (lm *logManagerImpl) WriteLog(level LogLevel, message string) error {
+	return lm.write(level, logMessage{text: message}, nil)
+}
+
+func (lm *logManagerImpl) WriteLogWithMetadata(level LogLevel, message string, metadata map[string]interface{}) error {
+	return lm.write(level, logMessage{text: message}, metadata)
+}
+
+// Enabled reports whether entries at level pass Config.MinLevel. Levels
+// without a known severity are always enabled.
+func (lm *logManagerImpl) Enabled(level LogLevel) bool {
+	if lm.minAt == 0 {
+		return true
+	}
+	sev, ok := level.Severity()
+	return !ok || sev >= lm.minAt
+}
+
+// write must be called directly by the exported write methods so the caller
+// is found at a fixed depth. The message and LogValuer metadata are only
+// evaluated for enabled levels.
+func (lm *logManagerImpl) write(level LogLevel, msg logMessage, metadata map[string]interface{}) error {
 	if lm.backend == nil {
 		return errors.New("backend not initialized")
 	}
 
+	// FATAL and PANIC terminate even when the entry is discarded or fails
+	switch level {
+	case LevelFatal:
+		defer lm.fatal(lm.runningCalls())
+	case LevelPanic:
+		// The panic needs the message even when the level is disabled
+		msg = logMessage{text: msg.String()}
+		defer lm.panic(msg.text, lm.runningCalls())
+	}
+
+	if !lm.Enabled(level) {
+		return nil
+	}
+	message := msg.String()
+	metadata = expandErrors(resolveMetadata(metadata))
+	if lm.config.CaptureCaller {
+		metadata = withCaller(metadata, callerSkip+lm.config.CallerSkip)
+	}
+	if sev, _ := level.Severity(); lm.stackAt > 0 && sev >= lm.stackAt {
+		metadata = withStack(metadata, callerSkip+lm.config.CallerSkip)
+	}
+
 	entry := LogEntry{
 		Level:     level,
 		Message:   message,
 		Timestamp: time.Now(),
+		Metadata:  metadata,
 	}
 
+	lm.stats.accept(level)
+
 	if lm.isAsync {
 		// Async mode: send to channel
 		select {
 		case lm.logChannel <- entry:
 			return nil
 		case <-time.After(100 * time.Millisecond):
-			return fmt.Errorf("log channel is full, log may be dropped")
+			err := fmt.Errorf("log channel is full, log may be dropped")
+			lm.stats.drop(level, err)
+			return err
 		}
 	} else {
-		// Sync mode: write immediately
-		if err := lm.backend.Write(entry); err != nil {
+		// Sync mode: write immediately and notify handlers
+		if err := lm.process(entry); err != nil {
 			return fmt.Errorf("failed to write log: %w", err)
 		}
 
-		// Notify handlers
-		lm.mu.Lock()
-		handlers := lm.handlers
-		lm.mu.Unlock()
-
-		for _, h := range handlers {
-			_ = h.Handle(entry)
-		}
-
 		return nil
 	}
 }
@@ -150,9 +356,85 @@ func (lm *logManagerImpl) ReadLogs(level LogLevel, filter LogFilter) ([]LogEntry
 	if lm.backend == nil {
 		return nil, errors.New("backend not initialized")
 	}
+
+	// Backends that cannot page may ignore Limit/Order/Cursor
+	if _, ok := lm.backend.(PagedBackend); !ok && (filter.Limit > 0 || filter.Order != "" || filter.Cursor != "") {
+		page, err := lm.ReadLogsPage(level, filter)
+		if err != nil {
+			return nil, err
+		}
+		return page.Entries, nil
+	}
+
 	return lm.backend.Read(level, filter)
 }
 
+func (lm *logManagerImpl) ReadLogsPage(level LogLevel, filter LogFilter) (LogPage, error) {
+	if lm.backend == nil {
+		return LogPage{}, errors.New("backend not initialized")
+	}
+
+	if pb, ok := lm.backend.(PagedBackend); ok {
+		return pb.ReadPage(level, filter)
+	}
+
+	// Backend cannot page: read everything matching and page in memory
+	all := filter
+	all.Limit, all.Order, all.Cursor = 0, "", ""
+	entries, err := lm.backend.Read(level, all)
+	if err != nil {
+		return LogPage{}, err
+	}
+	return readPage(filter, sliceScanner(entries))
+}
+
+func (lm *logManagerImpl) StreamLogs(ctx context.Context, level LogLevel, filter LogFilter) iter.Seq2[LogEntry, error] {
+	if lm.backend == nil {
+		return func(yield func(LogEntry, error) bool) {
+			yield(LogEntry{}, errors.New("backend not initialized"))
+		}
+	}
+
+	if sb, ok := lm.backend.(StreamingBackend); ok {
+		return sb.Stream(ctx, level, filter)
+	}
+
+	// Backend cannot stream: fall back to a buffered read
+	return func(yield func(LogEntry, error) bool) {
+		entries, err := lm.ReadLogs(level, filter)
+		if err != nil {
+			yield(LogEntry{}, err)
+			return
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				yield(LogEntry{}, err)
+				return
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (lm *logManagerImpl) Aggregate(ctx context.Context, level LogLevel, filter LogFilter, opts AggregateOptions) (AggregateResult, error) {
+	if lm.backend == nil {
+		return AggregateResult{}, errors.New("backend not initialized")
+	}
+	if err := opts.validate(); err != nil {
+		return AggregateResult{}, err
+	}
+
+	if ab, ok := lm.backend.(AggregatingBackend); ok {
+		return ab.Aggregate(ctx, level, filter, opts)
+	}
+
+	// Count in one streaming pass; paging does not apply to aggregates
+	filter.Limit, filter.Order, filter.Cursor = 0, "", ""
+	return aggregateEntries(lm.StreamLogs(ctx, level, filter), opts)
+}
+
 func (lm *logManagerImpl) ClearLogs(before time.Time) error {
 	if lm.backend == nil {
 		return errors.New("backend not initialized")
@@ -160,13 +442,16 @@ func (lm *logManagerImpl) ClearLogs(before time.Time) error {
 	return lm.backend.ClearLogs(before)
 }
 
-func (lm *logManagerImpl) RegisterLogHandler(handler LogHandler) {
-	lm.mu.Lock()
-	defer lm.mu.Unlock()
-	lm.handlers = append(lm.handlers, handler)
-}
-
 func (lm *logManagerImpl) Close() error {
+	first := false
+	lm.closeOnce.Do(func() {
+		first = true
+		close(lm.closed)
+	})
+	if !first {
+		return nil
+	}
+
 	// Stop async worker if running
 	if lm.isAsync && lm.done != nil {
 		close(lm.done)
@@ -174,8 +459,19 @@ func (lm *logManagerImpl) Close() error {
 		close(lm.logChannel)
 	}
 
+	lm.closeRecorder()
+
+	// Handlers and subscribers see every entry drained above
+	lm.stopHandlers()
+	lm.closeSubscribers()
+
 	if lm.backend == nil {
 		return nil
 	}
-	return lm.backend.Close()
+	// Parked entries that cannot be written are counted as dropped
+	var flushErr error
+	if err := lm.flushParked(); err != nil {
+		flushErr = fmt.Errorf("failed to flush parked logs: %w", err)
+	}
+	return errors.Join(flushErr, lm.backend.Close())
 }
@@ -10,6 +10,47 @@ import (
 	"time"
 )
 
+// newTestManager creates a LogManager that is closed when the test ends.
+// Without a backend in config it uses an unbounded memory backend.
+func newTestManager(t *testing.T, config Config) LogManager {
+	t.Helper()
+	if config.Backend == "" {
+		config.Backend = BackendMemory
+		config.BackendConfig = MemoryConfig{}
+	}
+	lm, err := NewLogManager(config)
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	t.Cleanup(func() { lm.Close() })
+	return lm
+}
+
+// newTestBackend initializes backend, closes it when the test ends and
+// writes n INFO entries "msg 0".."msg n-1", one second apart, with
+// metadata n=i
+func newTestBackend[B LogBackend](t *testing.T, backend B, config interface{}, n int) B {
+	t.Helper()
+	if err := backend.Init(config); err != nil {
+		t.Fatalf("Failed to init backend: %v", err)
+	}
+	t.Cleanup(func() { backend.Close() })
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		entry := LogEntry{
+			Level:     LevelInfo,
+			Message:   fmt.Sprintf("msg %d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Metadata:  map[string]interface{}{"n": i},
+		}
+		if err := backend.Write(entry); err != nil {
+			t.Fatalf("Failed to write log: %v", err)
+		}
+	}
+	return backend
+}
+
 func TestAsyncLogging(t *testing.T) {
 	// Create temp log file
 	tmpFile := "./test_async.log"
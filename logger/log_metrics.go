@@ -0,0 +1,245 @@
+// /logger/log_metrics.go
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultMetricBuckets are the histogram bounds used when a rule sets none
+var DefaultMetricBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+const (
+	metricLabelSep     = "\xff" // joins label values into a series key
+	defaultMetricsHelp = "Log entries matching the rule."
+)
+
+// MetricRule derives a metric from the entries matching Filter. Without a
+// Field the rule counts entries; with one it observes the numeric value of
+// that metadata key in a histogram and skips entries lacking it.
+type MetricRule struct {
+	Name    string    // Prometheus metric name
+	Help    string    // optional description
+	Filter  LogFilter // paging fields are ignored
+	Labels  []string  // metadata keys whose values label the series
+	Field   string    // numeric metadata key observed by a histogram
+	Buckets []float64 // histogram upper bounds, default DefaultMetricBuckets
+}
+
+// MetricSeries is one labeled series of a rule. Histogram series also carry
+// per-bucket counts laid out like LatencyHistogram.Counts.
+type MetricSeries struct {
+	Labels       map[string]string
+	Count        uint64
+	Sum          float64
+	BucketCounts []uint64
+}
+
+// LogMetrics is a LogHandler that maintains metrics derived from entries.
+// Register it on a LogManager and pass it to NewMetricsHandler to export it.
+type LogMetrics struct {
+	mu    sync.Mutex
+	rules []*metricRule
+}
+
+type metricRule struct {
+	MetricRule
+	matcher *entryMatcher
+	buckets []float64
+	series  map[string]*MetricSeries
+}
+
+// NewLogMetrics validates and compiles the rules
+func NewLogMetrics(rules ...MetricRule) (*LogMetrics, error) {
+	m := &LogMetrics{}
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		if !metricNamePattern.MatchString(rule.Name) {
+			return nil, fmt.Errorf("invalid metric name: %q", rule.Name)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate metric name: %s", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		matcher, err := newEntryMatcher("", rule.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %w", rule.Name, err)
+		}
+
+		buckets := rule.Buckets
+		if rule.Field != "" && len(buckets) == 0 {
+			buckets = DefaultMetricBuckets
+		}
+		buckets = append([]float64(nil), buckets...)
+		if !sort.Float64sAreSorted(buckets) {
+			return nil, fmt.Errorf("metric %s: buckets must be sorted", rule.Name)
+		}
+
+		m.rules = append(m.rules, &metricRule{
+			MetricRule: rule,
+			matcher:    matcher,
+			buckets:    buckets,
+			series:     make(map[string]*MetricSeries),
+		})
+	}
+	return m, nil
+}
+
+// Handle implements LogHandler
+func (m *LogMetrics) Handle(entry LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, rule := range m.rules {
+		if !rule.matcher.match(entry) {
+			continue
+		}
+
+		value := 0.0
+		if rule.Field != "" {
+			v, ok := numericValue(entry.Metadata[rule.Field], true)
+			if !ok {
+				continue
+			}
+			value = v
+		}
+
+		values := make([]string, len(rule.Labels))
+		for i, key := range rule.Labels {
+			if v, ok := entry.Metadata[key]; ok && v != nil {
+				values[i] = fmt.Sprint(v)
+			}
+		}
+
+		key := strings.Join(values, metricLabelSep)
+		s, ok := rule.series[key]
+		if !ok {
+			s = &MetricSeries{Labels: make(map[string]string, len(values))}
+			for i, label := range rule.Labels {
+				s.Labels[label] = values[i]
+			}
+			if rule.Field != "" {
+				s.BucketCounts = make([]uint64, len(rule.buckets)+1)
+			}
+			rule.series[key] = s
+		}
+
+		s.Count++
+		if rule.Field != "" {
+			s.Sum += value
+			i := sort.SearchFloat64s(rule.buckets, value)
+			s.BucketCounts[i]++
+		}
+	}
+	return nil
+}
+
+// Series returns a copy of every series of the named metric, sorted by labels
+func (m *LogMetrics) Series(name string) []MetricSeries {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rule := m.rule(name)
+	if rule == nil {
+		return nil
+	}
+	var out []MetricSeries
+	for _, key := range sortedSeriesKeys(rule.series) {
+		s := rule.series[key]
+		c := *s
+		c.Labels = make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			c.Labels[k] = v
+		}
+		c.BucketCounts = append([]uint64(nil), s.BucketCounts...)
+		out = append(out, c)
+	}
+	return out
+}
+
+// Count returns the number of entries counted by the named metric for the
+// given label values; missing labels match entries without that key
+func (m *LogMetrics) Count(name string, labels map[string]string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rule := m.rule(name)
+	if rule == nil {
+		return 0
+	}
+	values := make([]string, len(rule.Labels))
+	for i, label := range rule.Labels {
+		values[i] = labels[label]
+	}
+	if s, ok := rule.series[strings.Join(values, metricLabelSep)]; ok {
+		return s.Count
+	}
+	return 0
+}
+
+// rule must be called with m.mu held
+func (m *LogMetrics) rule(name string) *metricRule {
+	for _, rule := range m.rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// WritePrometheus implements MetricsWriter
+func (m *LogMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := &promWriter{w: w}
+	for _, rule := range m.rules {
+		help := rule.Help
+		if help == "" {
+			help = defaultMetricsHelp
+		}
+
+		if rule.Field == "" {
+			p.header(rule.Name, help, "counter")
+		} else {
+			p.header(rule.Name, help, "histogram")
+		}
+
+		for _, key := range sortedSeriesKeys(rule.series) {
+			s := rule.series[key]
+			pairs := make([]string, len(rule.Labels))
+			for i, label := range rule.Labels {
+				pairs[i] = labelPair(invalidLabelChars.ReplaceAllString(label, "_"), s.Labels[label])
+			}
+			labels := strings.Join(pairs, ",")
+
+			if rule.Field == "" {
+				p.sample(rule.Name, labels, float64(s.Count))
+			} else {
+				p.histogram(rule.Name, labels, rule.buckets, s.BucketCounts, s.Count, s.Sum)
+			}
+		}
+	}
+	return p.err
+}
+
+func sortedSeriesKeys(series map[string]*MetricSeries) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
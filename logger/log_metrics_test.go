@@ -0,0 +1,104 @@
+// /logger/log_metrics_test.go
+
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogMetrics(t *testing.T) {
+	metrics, err := NewLogMetrics(
+		MetricRule{
+			Name:   "app_errors_total",
+			Filter: LogFilter{Levels: []LogLevel{LevelError}},
+			Labels: []string{"component"},
+		},
+		MetricRule{
+			Name:    "app_request_seconds",
+			Filter:  LogFilter{Contains: "request"},
+			Field:   "duration",
+			Buckets: []float64{0.1, 1},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewLogMetrics failed: %v", err)
+	}
+
+	lm := newTestManager(t, Config{})
+	if _, err := lm.RegisterLogHandler(metrics); err != nil {
+		t.Fatalf("RegisterLogHandler failed: %v", err)
+	}
+
+	lm.WriteLogWithMetadata(LevelError, "disk full", map[string]interface{}{"component": "storage"})
+	lm.WriteLogWithMetadata(LevelError, "write failed", map[string]interface{}{"component": "storage"})
+	lm.WriteLogWithMetadata(LevelError, "timeout", map[string]interface{}{"component": "api"})
+	lm.WriteLogWithMetadata(LevelWarn, "slow", map[string]interface{}{"component": "storage"})
+	lm.WriteLogWithMetadata(LevelInfo, "request done", map[string]interface{}{"duration": 0.05})
+	lm.WriteLogWithMetadata(LevelInfo, "request done", map[string]interface{}{"duration": "0.5"})
+	lm.WriteLogWithMetadata(LevelInfo, "request done", map[string]interface{}{"duration": 3})
+	lm.WriteLog(LevelInfo, "request without duration")
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+
+	if n := metrics.Count("app_errors_total", map[string]string{"component": "storage"}); n != 2 {
+		t.Errorf("storage errors = %d, want 2", n)
+	}
+	if n := metrics.Count("app_errors_total", map[string]string{"component": "api"}); n != 1 {
+		t.Errorf("api errors = %d, want 1", n)
+	}
+
+	series := metrics.Series("app_request_seconds")
+	if len(series) != 1 {
+		t.Fatalf("got %d histogram series, want 1", len(series))
+	}
+	h := series[0]
+	if h.Count != 3 || h.Sum != 3.55 {
+		t.Errorf("count = %d, sum = %v", h.Count, h.Sum)
+	}
+	if want := []uint64{1, 1, 1}; !equalCounts(h.BucketCounts, want) {
+		t.Errorf("buckets = %v, want %v", h.BucketCounts, want)
+	}
+
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE app_errors_total counter",
+		`app_errors_total{component="api"} 1`,
+		`app_errors_total{component="storage"} 2`,
+		"# TYPE app_request_seconds histogram",
+		`app_request_seconds_bucket{le="1"} 2`,
+		`app_request_seconds_bucket{le="+Inf"} 3`,
+		"app_request_seconds_count 3",
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestLogMetricsInvalidRules(t *testing.T) {
+	for _, rules := range [][]MetricRule{
+		{{Name: "bad name"}},
+		{{Name: "dup"}, {Name: "dup"}},
+		{{Name: "bad_regex", Filter: LogFilter{Regex: "("}}},
+		{{Name: "unsorted", Field: "x", Buckets: []float64{2, 1}}},
+	} {
+		if _, err := NewLogMetrics(rules...); err == nil {
+			t.Errorf("expected error for %+v", rules)
+		}
+	}
+}
+
+func equalCounts(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
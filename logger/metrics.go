@@ -0,0 +1,169 @@
+// /logger/metrics.go
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricsContentType is the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsWriter adds metrics of its own to the exposition endpoint
+type MetricsWriter interface {
+	WritePrometheus(w io.Writer) error
+}
+
+// NewMetricsHandler serves the LogManager statistics in the Prometheus text
+// exposition format, followed by the output of every extra writer. The
+// output is rendered first, so a failing writer results in a 500 instead of
+// a truncated scrape. There is no file rotation count, as FileBackend does
+// not rotate files.
+func NewMetricsHandler(lm LogManager, extra ...MetricsWriter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		err := WritePrometheus(&buf, lm.Stats())
+		for _, mw := range extra {
+			if err != nil {
+				break
+			}
+			err = mw.WritePrometheus(&buf)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to render metrics: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", metricsContentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+// WritePrometheus renders stats in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, stats LogStats) error {
+	p := &promWriter{w: w}
+
+	p.levelCounter("aidmslog_entries_accepted_total", "Log entries accepted by WriteLog.", stats.Accepted)
+	p.levelCounter("aidmslog_entries_written_total", "Log entries written to the backend.", stats.Written)
+	p.levelCounter("aidmslog_entries_dropped_total", "Log entries lost to a full queue, failed writes or the flight recorder.", stats.Dropped)
+
+	p.header("aidmslog_queue_length", "Entries waiting in the async queue.", "gauge")
+	p.sample("aidmslog_queue_length", "", float64(stats.QueueLength))
+	p.header("aidmslog_queue_capacity", "Capacity of the async queue.", "gauge")
+	p.sample("aidmslog_queue_capacity", "", float64(stats.QueueCapacity))
+
+	p.header("aidmslog_handlers", "Registered log handlers.", "gauge")
+	p.sample("aidmslog_handlers", "", float64(stats.HandlerCount))
+	p.header("aidmslog_handler_errors_total", "Log handler failures, timeouts and dropped deliveries.", "counter")
+	p.sample("aidmslog_handler_errors_total", "", float64(stats.HandlerErrors))
+	p.header("aidmslog_subscribers", "Live subscriptions.", "gauge")
+	p.sample("aidmslog_subscribers", "", float64(stats.Subscribers))
+
+	p.latencyHistogram("aidmslog_backend_write_duration_seconds", "Latency of backend write attempts.", stats.WriteLatency)
+
+	p.header("aidmslog_circuit_breaker_state", "Circuit breaker state: 0 closed, 1 open, 2 half-open.", "gauge")
+	p.sample("aidmslog_circuit_breaker_state", "", float64(stats.BreakerState))
+	p.header("aidmslog_parked_entries", "Entries parked while the circuit breaker is open.", "gauge")
+	p.sample("aidmslog_parked_entries", "", float64(stats.Parked))
+
+	p.header("aidmslog_bytes_written_total", "Bytes written by the backend.", "counter")
+	p.sample("aidmslog_bytes_written_total", "", float64(stats.BytesWritten))
+	p.header("aidmslog_malformed_lines_total", "Stored lines skipped by reads because they could not be decoded.", "counter")
+	p.sample("aidmslog_malformed_lines_total", "", float64(stats.MalformedLines))
+
+	return p.err
+}
+
+// promWriter writes exposition lines and keeps the first error
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *promWriter) header(name, help, typ string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one value; labels are already formatted as `a="b",c="d"`
+func (p *promWriter) sample(name, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	p.printf("%s %s\n", name, formatFloat(value))
+}
+
+// levelCounter writes a counter per level; the built-in levels are always present
+func (p *promWriter) levelCounter(name, help string, counts map[LogLevel]uint64) {
+	p.header(name, help, "counter")
+	for _, level := range sortedLevels(counts) {
+		p.sample(name, labelPair("level", string(level)), float64(counts[level]))
+	}
+}
+
+// latencyHistogram writes h as a histogram in seconds
+func (p *promWriter) latencyHistogram(name, help string, h LatencyHistogram) {
+	bounds := make([]float64, len(h.Buckets))
+	for i, b := range h.Buckets {
+		bounds[i] = b.Seconds()
+	}
+	p.header(name, help, "histogram")
+	p.histogram(name, "", bounds, h.Counts, h.Count, h.Sum.Seconds())
+}
+
+// histogram writes cumulative buckets from per-bucket counts; labels are
+// prepended to the le label
+func (p *promWriter) histogram(name, labels string, bounds []float64, counts []uint64, count uint64, sum float64) {
+	prefix := ""
+	if labels != "" {
+		prefix = labels + ","
+	}
+	var cumulative uint64
+	for i, bound := range bounds {
+		if i < len(counts) {
+			cumulative += counts[i]
+		}
+		p.sample(name+"_bucket", prefix+labelPair("le", formatFloat(bound)), float64(cumulative))
+	}
+	p.sample(name+"_bucket", prefix+labelPair("le", "+Inf"), float64(count))
+	p.sample(name+"_sum", labels, sum)
+	p.sample(name+"_count", labels, float64(count))
+}
+
+// sortedLevels lists the known levels by severity, then any others by name
+func sortedLevels(counts map[LogLevel]uint64) []LogLevel {
+	levels := Levels()
+	var other []LogLevel
+	for level := range counts {
+		if _, ok := level.Severity(); !ok {
+			other = append(other, level)
+		}
+	}
+	sort.Slice(other, func(i, j int) bool { return other[i] < other[j] })
+	return append(levels, other...)
+}
+
+func labelPair(name, value string) string {
+	return name + `="` + escapeLabel(value) + `"`
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
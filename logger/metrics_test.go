@@ -0,0 +1,109 @@
+// /logger/metrics_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type staticMetrics string
+
+func (m staticMetrics) WritePrometheus(w io.Writer) error {
+	_, err := io.WriteString(w, string(m))
+	return err
+}
+
+func TestMetricsHandler(t *testing.T) {
+	lm := newTestManager(t, Config{
+		Backend:       BackendFile,
+		BackendConfig: FileConfig{FilePath: filepath.Join(t.TempDir(), "app.log")},
+	})
+	lm.WriteLog(LevelInfo, "one")
+	lm.WriteLog(LevelInfo, "two")
+	lm.WriteLog(LevelError, "three")
+
+	srv := httptest.NewServer(NewMetricsHandler(lm, staticMetrics("custom_metric 1\n")))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	text := string(body)
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type = %q", ct)
+	}
+
+	size := lm.Stats().BytesWritten
+	for _, want := range []string{
+		"# TYPE aidmslog_entries_written_total counter",
+		`aidmslog_entries_written_total{level="INFO"} 2`,
+		`aidmslog_entries_written_total{level="ERROR"} 1`,
+		`aidmslog_entries_dropped_total{level="DEBUG"} 0`,
+		"# TYPE aidmslog_backend_write_duration_seconds histogram",
+		`aidmslog_backend_write_duration_seconds_bucket{le="+Inf"} 3`,
+		"aidmslog_backend_write_duration_seconds_count 3",
+		"aidmslog_queue_length 0",
+		fmt.Sprintf("aidmslog_bytes_written_total %d", size),
+		"aidmslog_malformed_lines_total 0",
+		"custom_metric 1",
+	} {
+		if !strings.Contains(text, want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	if size == 0 {
+		t.Error("expected bytes written to be counted")
+	}
+}
+
+type failingMetrics struct{}
+
+func (failingMetrics) WritePrometheus(w io.Writer) error {
+	io.WriteString(w, "partial_metric")
+	return errors.New("collector failed")
+}
+
+func TestMetricsHandlerError(t *testing.T) {
+	lm := newTestManager(t, Config{})
+	rec := httptest.NewRecorder()
+	NewMetricsHandler(lm, failingMetrics{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "aidmslog_") || !strings.Contains(body, "collector failed") {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestWritePrometheusHistogramIsCumulative(t *testing.T) {
+	var b strings.Builder
+	stats := LogStats{WriteLatency: LatencyHistogram{
+		Buckets: latencyBuckets[:2],
+		Counts:  []uint64{1, 2, 3},
+		Count:   6,
+	}}
+	if err := WritePrometheus(&b, stats); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`aidmslog_backend_write_duration_seconds_bucket{le="0.0001"} 1`,
+		`aidmslog_backend_write_duration_seconds_bucket{le="0.0005"} 3`,
+		`aidmslog_backend_write_duration_seconds_bucket{le="+Inf"} 6`,
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
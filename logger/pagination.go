@@ -0,0 +1,163 @@
+// /logger/pagination.go
+
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// pageCursor is the decoded form of LogFilter.Cursor / LogPage cursors.
+// It marks a boundary between two entries in storage order: file backends
+// use a byte offset, SQL backends use the (timestamp, id) key.
+type pageCursor struct {
+	Offset  int64 `json:"o,omitempty"`
+	Time    int64 `json:"t,omitempty"`
+	ID      int64 `json:"i,omitempty"`
+	Forward bool  `json:"f,omitempty"` // scan toward newer entries
+}
+
+func (c pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return &c, nil
+}
+
+// pageHit is a matching entry together with the cursors on either side of it
+type pageHit struct {
+	entry  LogEntry
+	before pageCursor // continues toward older entries
+	after  pageCursor // continues toward newer entries
+}
+
+// pageScanner visits matching entries starting at the boundary described by
+// from (nil means the oldest or newest edge), toward newer entries when forward
+// is true. max is a hint of how many hits will be consumed (0 = all); scanning
+// stops as soon as visit returns false.
+type pageScanner func(from *pageCursor, forward bool, max int, visit func(pageHit) bool) error
+
+// readPage implements Limit/Order/Cursor on top of a backend specific scanner
+func readPage(filter LogFilter, scan pageScanner) (LogPage, error) {
+	cur, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return LogPage{}, err
+	}
+
+	desc := filter.Order == OrderDesc
+	forward := !desc
+	if cur != nil {
+		forward = cur.Forward
+	}
+	// Paging back toward the first page scans against the display order
+	backtrack := cur != nil && forward == desc
+
+	max := 0
+	if filter.Limit > 0 {
+		max = filter.Limit + 1
+	}
+
+	var hits []pageHit
+	more := false
+	err = scan(cur, forward, max, func(h pageHit) bool {
+		if filter.Limit > 0 && len(hits) == filter.Limit {
+			more = true
+			return false
+		}
+		hits = append(hits, h)
+		return true
+	})
+	if err != nil {
+		return LogPage{}, err
+	}
+
+	if backtrack {
+		for i, j := 0, len(hits)-1; i < j; i, j = i+1, j-1 {
+			hits[i], hits[j] = hits[j], hits[i]
+		}
+	}
+
+	page := LogPage{}
+	if len(hits) == 0 {
+		return page, nil
+	}
+
+	page.Entries = make([]LogEntry, len(hits))
+	for i, h := range hits {
+		page.Entries[i] = h.entry
+	}
+
+	first, last := hits[0], hits[len(hits)-1]
+	next, prev := last.after, first.before
+	if desc {
+		next, prev = last.before, first.after
+	}
+
+	if backtrack {
+		page.NextCursor = next.encode()
+		if more {
+			page.PrevCursor = prev.encode()
+		}
+	} else {
+		if more {
+			page.NextCursor = next.encode()
+		}
+		if cur != nil {
+			page.PrevCursor = prev.encode()
+		}
+	}
+
+	return page, nil
+}
+
+// sliceScanner pages over entries already held in memory, in storage order.
+// Cursor offsets are indexes into entries.
+func sliceScanner(entries []LogEntry) pageScanner {
+	return func(from *pageCursor, forward bool, max int, visit func(pageHit) bool) error {
+		hit := func(i int) pageHit {
+			return pageHit{
+				entry:  entries[i],
+				before: pageCursor{Offset: int64(i)},
+				after:  pageCursor{Offset: int64(i + 1), Forward: true},
+			}
+		}
+
+		if forward {
+			start := 0
+			if from != nil {
+				start = int(from.Offset)
+			}
+			for i := start; i >= 0 && i < len(entries); i++ {
+				if !visit(hit(i)) {
+					return nil
+				}
+			}
+			return nil
+		}
+
+		end := len(entries)
+		if from != nil && int(from.Offset) < end {
+			end = int(from.Offset)
+		}
+		for i := end - 1; i >= 0; i-- {
+			if !visit(hit(i)) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,537 @@
+// /logger/query.go
+
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Query language
+//
+//	query      := or
+//	or         := and { "or" and }
+//	and        := not { "and" not }
+//	not        := "not" not | "(" query ")" | comparison
+//	comparison := field op value
+//	field      := "level" | "msg" | "message" | "time" | "meta.<key>"
+//	op         := "=" | "!=" | "<" | "<=" | ">" | ">=" | "~" | "!~"
+//	value      := "quoted string" | number | duration | word
+//
+// Examples:
+//
+//	level>=WARN and msg~"disk" and meta.node="gpu-3" and time>-1h
+//	not (level=DEBUG or meta.component="health")
+//
+// "~" and "!~" match a regular expression. Level comparisons use severity.
+// Time values are RFC3339 timestamps, "now", or durations relative to the
+// time the query was parsed (-1h). Comparisons on a missing metadata key are
+// false.
+
+// QueryField identifies the entry field a comparison applies to
+type QueryField string
+
+const (
+	FieldLevel   QueryField = "level"
+	FieldMessage QueryField = "msg"
+	FieldTime    QueryField = "time"
+	FieldMeta    QueryField = "meta"
+)
+
+// QueryOp is a comparison operator of the query language
+type QueryOp string
+
+const (
+	QueryEq       QueryOp = "="
+	QueryNe       QueryOp = "!="
+	QueryLt       QueryOp = "<"
+	QueryLe       QueryOp = "<="
+	QueryGt       QueryOp = ">"
+	QueryGe       QueryOp = ">="
+	QueryMatch    QueryOp = "~"
+	QueryNotMatch QueryOp = "!~"
+)
+
+// QueryNode is a node of a parsed query
+type QueryNode interface {
+	// Eval reports whether entry satisfies the node
+	Eval(entry LogEntry) bool
+	String() string
+}
+
+// AndNode is satisfied when both sides are
+type AndNode struct {
+	Left, Right QueryNode
+}
+
+// OrNode is satisfied when either side is
+type OrNode struct {
+	Left, Right QueryNode
+}
+
+// NotNode negates its operand
+type NotNode struct {
+	Operand QueryNode
+}
+
+// CompareNode compares a field of the entry with a literal value
+type CompareNode struct {
+	Field QueryField
+	Key   string // metadata key when Field is FieldMeta
+	Op    QueryOp
+	Value string // literal as written, unquoted
+	Pos   int    // byte offset of the comparison in the query
+
+	num   float64
+	isNum bool
+	t     time.Time
+	re    *regexp.Regexp
+}
+
+func (n *AndNode) Eval(entry LogEntry) bool { return n.Left.Eval(entry) && n.Right.Eval(entry) }
+func (n *OrNode) Eval(entry LogEntry) bool  { return n.Left.Eval(entry) || n.Right.Eval(entry) }
+func (n *NotNode) Eval(entry LogEntry) bool { return !n.Operand.Eval(entry) }
+
+func (n *AndNode) String() string { return "(" + n.Left.String() + " and " + n.Right.String() + ")" }
+func (n *OrNode) String() string  { return "(" + n.Left.String() + " or " + n.Right.String() + ")" }
+func (n *NotNode) String() string { return "not " + n.Operand.String() }
+
+func (n *CompareNode) String() string {
+	field := string(n.Field)
+	if n.Field == FieldMeta {
+		field += "." + n.Key
+	}
+	value := quoteQuery(n.Value)
+	if n.isNum && n.Field != FieldTime {
+		value = n.Value
+	}
+	return field + string(n.Op) + value
+}
+
+func (n *CompareNode) Eval(entry LogEntry) bool {
+	switch n.Field {
+	case FieldLevel:
+		if n.Op == QueryEq || n.Op == QueryNe {
+			return compareResult(n.Op, strings.Compare(string(entry.Level), n.Value))
+		}
+		sev, ok := entry.Level.Severity()
+		if !ok {
+			return false
+		}
+		return compareResult(n.Op, sev-int(n.num))
+
+	case FieldMessage:
+		if n.re != nil {
+			return n.re.MatchString(entry.Message) == (n.Op == QueryMatch)
+		}
+		return compareResult(n.Op, strings.Compare(entry.Message, n.Value))
+
+	case FieldTime:
+		return compareResult(n.Op, entry.Timestamp.Compare(n.t))
+
+	case FieldMeta:
+		v, ok := entry.Metadata[n.Key]
+		if !ok || v == nil {
+			return false
+		}
+		if n.re != nil {
+			return n.re.MatchString(metadataText(v)) == (n.Op == QueryMatch)
+		}
+		return matchMetadata(entry, n.predicate(), n.num, n.isNum)
+	}
+	return false
+}
+
+// predicate returns the metadata comparison as a MetadataPredicate
+func (n *CompareNode) predicate() MetadataPredicate {
+	p := MetadataPredicate{Key: n.Key, Op: CompareOp(n.Op), Value: n.Value}
+	if n.isNum {
+		p.Value = n.num
+	}
+	return p
+}
+
+// compareResult applies a non-regex operator to a three-way comparison
+func compareResult(op QueryOp, cmp int) bool {
+	switch op {
+	case QueryEq:
+		return cmp == 0
+	case QueryNe:
+		return cmp != 0
+	case QueryLt:
+		return cmp < 0
+	case QueryLe:
+		return cmp <= 0
+	case QueryGt:
+		return cmp > 0
+	case QueryGe:
+		return cmp >= 0
+	}
+	return false
+}
+
+// QueryError reports a syntax or semantic error at a byte offset of the query
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query error at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// ParseQuery parses a filter expression into an AST
+func ParseQuery(query string) (QueryNode, error) {
+	return parseQuery(query, time.Now())
+}
+
+// ParseFilter parses a filter expression into a LogFilter
+func ParseFilter(query string) (LogFilter, error) {
+	node, err := ParseQuery(query)
+	if err != nil {
+		return LogFilter{}, err
+	}
+	return LogFilter{Expr: node}, nil
+}
+
+func parseQuery(query string, now time.Time) (QueryNode, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, now: now}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t queryToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return quoteQuery(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(':
+			tokens = append(tokens, queryToken{tokLParen, "(", i})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, queryToken{tokRParen, ")", i})
+			i++
+
+		case c == '"':
+			start := i
+			i++
+			for i < len(query) && query[i] != '"' {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(query) {
+				return nil, &QueryError{Pos: start, Msg: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, queryToken{tokString, unquoteQuery(query[start+1 : i-1]), start})
+
+		case strings.ContainsRune("=!<>~", rune(c)):
+			start := i
+			op := string(c)
+			if i+1 < len(query) {
+				switch two := query[i : i+2]; two {
+				case "!=", "<=", ">=", "!~":
+					op = two
+				}
+			}
+			if op == "!" {
+				return nil, &QueryError{Pos: start, Msg: `expected "!=" or "!~"`}
+			}
+			i += len(op)
+			tokens = append(tokens, queryToken{tokOp, op, start})
+
+		case c == '-' || c == '+' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(query) && isWordRune(rune(query[i])) {
+				i++
+			}
+			tokens = append(tokens, queryToken{tokNumber, query[start:i], start})
+
+		default:
+			start := i
+			for i < len(query) {
+				r, size := utf8.DecodeRuneInString(query[i:])
+				if !isWordRune(r) {
+					break
+				}
+				i += size
+			}
+			if i == start {
+				return nil, &QueryError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", query[start:start+1])}
+			}
+			tokens = append(tokens, queryToken{tokWord, query[start:i], start})
+		}
+	}
+	return append(tokens, queryToken{tokEOF, "", len(query)}), nil
+}
+
+// unquoteQuery resolves \" and \\ escapes. Other backslashes are kept so
+// that regular expressions such as "\d+ms" need no double escaping.
+func unquoteQuery(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func quoteQuery(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	now    time.Time
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokWord && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &OrNode{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &AndNode{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseNot() (QueryNode, error) {
+	if p.keyword("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Operand: operand}, nil
+	}
+
+	if tok := p.peek(); tok.kind == tokLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &QueryError{Pos: closing.pos, Msg: fmt.Sprintf(`expected ")" but found %s`, closing)}
+		}
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (QueryNode, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokWord {
+		return nil, &QueryError{Pos: fieldTok.pos, Msg: fmt.Sprintf("expected field but found %s", fieldTok)}
+	}
+
+	node := &CompareNode{Pos: fieldTok.pos}
+	name := strings.ToLower(fieldTok.text)
+	switch {
+	case name == "level":
+		node.Field = FieldLevel
+	case name == "msg" || name == "message":
+		node.Field = FieldMessage
+	case name == "time" || name == "timestamp":
+		node.Field = FieldTime
+	case strings.HasPrefix(name, "meta.") && len(name) > len("meta."):
+		node.Field = FieldMeta
+		node.Key = fieldTok.text[len("meta."):]
+	default:
+		return nil, &QueryError{Pos: fieldTok.pos, Msg: fmt.Sprintf("unknown field %q", fieldTok.text)}
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokOp {
+		return nil, &QueryError{Pos: opTok.pos, Msg: fmt.Sprintf("expected operator but found %s", opTok)}
+	}
+	node.Op = QueryOp(opTok.text)
+
+	valueTok := p.next()
+	switch valueTok.kind {
+	case tokString, tokNumber, tokWord:
+		node.Value = valueTok.text
+	default:
+		return nil, &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("expected value but found %s", valueTok)}
+	}
+
+	if err := p.bind(node, opTok, valueTok); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// bind validates the operator for the field and pre-computes the value
+func (p *queryParser) bind(node *CompareNode, opTok, valueTok queryToken) error {
+	isRegex := node.Op == QueryMatch || node.Op == QueryNotMatch
+	opErr := func() error {
+		return &QueryError{Pos: opTok.pos, Msg: fmt.Sprintf("operator %q is not supported for %s", node.Op, node.Field)}
+	}
+
+	switch node.Field {
+	case FieldLevel:
+		if isRegex {
+			return opErr()
+		}
+		node.Value = strings.ToUpper(node.Value)
+		if node.Op != QueryEq && node.Op != QueryNe {
+			sev, ok := LogLevel(node.Value).Severity()
+			if !ok {
+				return &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("unknown level %q", valueTok.text)}
+			}
+			node.num = float64(sev)
+		}
+
+	case FieldMessage:
+		if node.Op != QueryEq && node.Op != QueryNe && !isRegex {
+			return opErr()
+		}
+
+	case FieldTime:
+		if node.Op == QueryEq || node.Op == QueryNe || isRegex {
+			return opErr()
+		}
+		t, err := p.timeValue(valueTok)
+		if err != nil {
+			return err
+		}
+		node.t = t
+		node.isNum = true
+
+	case FieldMeta:
+		if !isRegex && valueTok.kind == tokNumber {
+			f, err := strconv.ParseFloat(valueTok.text, 64)
+			if err != nil {
+				return &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("invalid number %q", valueTok.text)}
+			}
+			node.num = f
+			node.isNum = true
+		}
+	}
+
+	if isRegex {
+		re, err := regexp.Compile(node.Value)
+		if err != nil {
+			return &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("invalid regex: %v", err)}
+		}
+		node.re = re
+	}
+	return nil
+}
+
+func (p *queryParser) timeValue(tok queryToken) (time.Time, error) {
+	if strings.EqualFold(tok.text, "now") {
+		return p.now, nil
+	}
+	if tok.kind == tokNumber {
+		if d, err := time.ParseDuration(tok.text); err == nil {
+			return p.now.Add(d), nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, tok.text); err == nil {
+		return t, nil
+	}
+	return time.Time{}, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("invalid time %q, expected RFC3339, now or a duration like -1h", tok.text)}
+}
@@ -0,0 +1,96 @@
+// /logger/query_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseQueryEval(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := LogEntry{
+		Level:     LevelWarn,
+		Message:   "disk usage at 95%",
+		Timestamp: now.Add(-10 * time.Minute),
+		Metadata:  map[string]interface{}{"node": "gpu-3", "usage": float64(95)},
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`level>=WARN and msg~"disk" and meta.node="gpu-3" and time>-1h`, true},
+		{`level>WARN`, false},
+		{`level=warn`, true},
+		{`msg~"usage at \d+%"`, true},
+		{`msg!~"disk"`, false},
+		{`meta.usage>=90 and meta.usage<100`, true},
+		{`meta.missing!="x"`, false},
+		{`not meta.missing="x"`, true},
+		{`time<-1h or (level=ERROR)`, false},
+		{`not (level=DEBUG or meta.node="gpu-1")`, true},
+		{`level=INFO or level=WARN and msg="nope"`, false},
+	}
+
+	for _, tt := range tests {
+		node, err := parseQuery(tt.query, now)
+		if err != nil {
+			t.Errorf("parseQuery(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got := node.Eval(entry); got != tt.want {
+			t.Errorf("%q (parsed as %s) = %v, want %v", tt.query, node, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{`level>=`, 7},
+		{`level>=LOUD`, 7},
+		{`msg>"a"`, 3},
+		{`foo="bar"`, 0},
+		{`(level=INFO`, 11},
+		{`msg="open`, 4},
+		{`level=INFO and`, 14},
+		{`msg~"("`, 4},
+		{`time>yesterday`, 5},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		var qe *QueryError
+		if !errors.As(err, &qe) {
+			t.Errorf("ParseQuery(%q) expected QueryError, got %v", tt.query, err)
+			continue
+		}
+		if qe.Pos != tt.pos {
+			t.Errorf("ParseQuery(%q) error at %d, want %d (%v)", tt.query, qe.Pos, tt.pos, qe)
+		}
+	}
+}
+
+func TestQueryToSQL(t *testing.T) {
+	now := time.Unix(0, 5000)
+	node, err := parseQuery(`level>=ERROR and (msg~"disk" or not meta.node="gpu-3") and time>now`, now)
+	if err != nil {
+		t.Fatalf("parseQuery failed: %v", err)
+	}
+
+	q := &sqlQuery{dialect: sqlDialect{driver: "postgres"}}
+	q.filter("", LogFilter{Expr: node})
+
+	want := " WHERE ((level IN ($1, $2, $3) AND (message ~ $4 OR NOT (COALESCE((metadata::jsonb ->> $5) = $6, FALSE)))) AND ts > $7)"
+	if got := q.whereClause(); got != want {
+		t.Errorf("Unexpected clause:\n got:%s\nwant:%s", got, want)
+	}
+	if fmt.Sprint(q.args) != "[ERROR FATAL PANIC disk node gpu-3 5000]" {
+		t.Errorf("Unexpected args: %v", q.args)
+	}
+}
@@ -0,0 +1,278 @@
+// /logger/resilience.go
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// ErrParkedFull is returned when the circuit breaker is open and the parked
+// entry buffer is full; the entry is dropped
+var ErrParkedFull = errors.New("backend unavailable and parked entries buffer is full, log dropped")
+
+// RetryPolicy controls retries of a failed write. The zero value disables retries.
+type RetryPolicy struct {
+	MaxAttempts    int           // total attempts including the first, <= 1 means no retry
+	InitialBackoff time.Duration // delay before the first retry, default 10ms
+	MaxBackoff     time.Duration // upper bound of a single delay, default 1s
+	Multiplier     float64       // backoff growth per attempt, default 2
+	Jitter         float64       // random fraction (0..1) subtracted from each delay
+}
+
+// backoff returns the delay before retry number n (1-based)
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	if d <= 0 {
+		d = 10 * time.Millisecond
+	}
+	max := p.MaxBackoff
+	if max <= 0 {
+		max = time.Second
+	}
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 2
+	}
+
+	delay := float64(d)
+	for i := 1; i < n && delay < float64(max); i++ {
+		delay *= mult
+	}
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= delay * jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// do calls fn until it succeeds, fails permanently or the attempts are exhausted
+func (p RetryPolicy) do(fn func() error) error {
+	return p.doContext(context.Background(), fn)
+}
+
+// doContext is do that gives up without further attempts once ctx is done
+func (p RetryPolicy) doContext(ctx context.Context, fn func() error) error {
+	err := fn()
+	for n := 1; err != nil && n < p.MaxAttempts; n++ {
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			break
+		}
+		timer := time.NewTimer(p.backoff(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = fn()
+	}
+	return err
+}
+
+// BreakerConfig configures the circuit breaker around backend writes.
+// The zero value disables the breaker.
+type BreakerConfig struct {
+	FailureThreshold int           // consecutive failed writes that open the breaker
+	OpenTimeout      time.Duration // time before a probe write is allowed, default 30s
+	MaxParked        int           // entries held while open, default 10000
+}
+
+// BreakerState is the state of the circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // writes go to the backend
+	BreakerOpen                         // writes are parked
+	BreakerHalfOpen                     // a probe write decides whether to close
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type circuitBreaker struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	now      func() time.Time
+	onChange func(from, to BreakerState)
+}
+
+func newCircuitBreaker(config BreakerConfig, onChange func(from, to BreakerState)) *circuitBreaker {
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.MaxParked <= 0 {
+		config.MaxParked = 10000
+	}
+	return &circuitBreaker{config: config, now: time.Now, onChange: onChange}
+}
+
+// setState must be called with cb.mu held; the callback runs after unlock
+func (cb *circuitBreaker) setState(to BreakerState) func() {
+	from := cb.state
+	if from == to {
+		return func() {}
+	}
+	cb.state = to
+	if to == BreakerOpen {
+		cb.openedAt = cb.now()
+	}
+	return func() {
+		if cb.onChange != nil {
+			cb.onChange(from, to)
+		}
+	}
+}
+
+// allow reports whether a write may reach the backend
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	notify := func() {}
+	defer func() { notify() }()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case BreakerOpen:
+		if cb.now().Sub(cb.openedAt) < cb.config.OpenTimeout {
+			return false
+		}
+		notify = cb.setState(BreakerHalfOpen)
+		cb.probing = true
+		return true
+	case BreakerHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	}
+	return true
+}
+
+func (cb *circuitBreaker) success() {
+	cb.mu.Lock()
+	notify := func() {}
+	defer func() { notify() }()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.probing = false
+	notify = cb.setState(BreakerClosed)
+}
+
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	notify := func() {}
+	defer func() { notify() }()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.probing = false
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.config.FailureThreshold {
+		notify = cb.setState(BreakerOpen)
+	}
+}
+
+func (cb *circuitBreaker) currentState() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// writeBackend writes entry with the configured retry policy and circuit
+// breaker. While the breaker is open entries are parked and written, in
+// order, ahead of the next entry that is allowed through.
+func (lm *logManagerImpl) writeBackend(entry LogEntry) error {
+	if lm.breaker == nil {
+		return lm.config.Retry.do(func() error { return lm.timedWrite(entry) })
+	}
+
+	lm.parkMu.Lock()
+	defer lm.parkMu.Unlock()
+
+	if !lm.breaker.allow() {
+		return lm.park(entry)
+	}
+
+	// Flush parked entries first to keep them in order
+	for len(lm.parked) > 0 {
+		if err := lm.config.Retry.do(func() error { return lm.timedWrite(lm.parked[0]) }); err != nil {
+			lm.breaker.failure()
+			return lm.park(entry)
+		}
+		lm.parked[0] = LogEntry{}
+		lm.parked = lm.parked[1:]
+	}
+
+	if err := lm.config.Retry.do(func() error { return lm.timedWrite(entry) }); err != nil {
+		lm.breaker.failure()
+		if lm.breaker.currentState() == BreakerOpen {
+			return lm.park(entry)
+		}
+		return err
+	}
+	lm.breaker.success()
+	return nil
+}
+
+// park buffers entry while the breaker is open; lm.parkMu must be held
+func (lm *logManagerImpl) park(entry LogEntry) error {
+	if len(lm.parked) >= lm.breaker.config.MaxParked {
+		return ErrParkedFull
+	}
+	lm.parked = append(lm.parked, entry)
+	return nil
+}
+
+// flushParked makes a final attempt to write parked entries on Close
+func (lm *logManagerImpl) flushParked() error {
+	if lm.breaker == nil {
+		return nil
+	}
+
+	lm.parkMu.Lock()
+	defer lm.parkMu.Unlock()
+
+	for len(lm.parked) > 0 {
+		if err := lm.timedWrite(lm.parked[0]); err != nil {
+			for _, entry := range lm.parked {
+				lm.stats.drop(entry.Level, err)
+			}
+			return err
+		}
+		lm.parked = lm.parked[1:]
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// /logger/resilience_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyBackend fails writes while failing is set and records the rest
+type flakyBackend struct {
+	LogBackend
+	mu       sync.Mutex
+	failing  bool
+	failNext int
+	attempts int
+	written  []string
+}
+
+func (b *flakyBackend) Write(entry LogEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts++
+	if b.failing || b.failNext > 0 {
+		b.failNext--
+		return errors.New("backend down")
+	}
+	b.written = append(b.written, entry.Message)
+	return b.LogBackend.Write(entry)
+}
+
+func (b *flakyBackend) setFailing(failing bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failing = failing
+}
+
+func withFlakyBackend(t *testing.T, config Config) (LogManager, *flakyBackend) {
+	t.Helper()
+	config.Backend = BackendFile
+	config.BackendConfig = FileConfig{FilePath: t.TempDir() + "/app.log"}
+
+	lm, err := NewLogManager(config)
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	t.Cleanup(func() { lm.Close() })
+
+	impl := lm.(*logManagerImpl)
+	fb := &flakyBackend{LogBackend: impl.backend}
+	impl.backend = fb
+	return lm, fb
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond, Multiplier: 3}
+
+	var got []time.Duration
+	for n := 1; n <= 4; n++ {
+		got = append(got, p.backoff(n))
+	}
+	if fmt.Sprint(got) != "[10ms 30ms 50ms 50ms]" {
+		t.Errorf("Unexpected backoff sequence %v", got)
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(2); d < 15*time.Millisecond || d > 30*time.Millisecond {
+			t.Fatalf("Jittered backoff %v out of range", d)
+		}
+	}
+}
+
+func TestWriteRetry(t *testing.T) {
+	lm, fb := withFlakyBackend(t, Config{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+
+	fb.failNext = 2
+	if err := lm.WriteLog(LevelInfo, "eventually"); err != nil {
+		t.Fatalf("Expected write to succeed after retries: %v", err)
+	}
+	if fb.attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", fb.attempts)
+	}
+}
+
+func TestCircuitBreakerParksEntries(t *testing.T) {
+	var mu sync.Mutex
+	var transitions []string
+
+	lm, fb := withFlakyBackend(t, Config{
+		CircuitBreaker: BreakerConfig{FailureThreshold: 2, OpenTimeout: 30 * time.Millisecond},
+		OnBreakerStateChange: func(from, to BreakerState) {
+			mu.Lock()
+			defer mu.Unlock()
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+
+	fb.setFailing(true)
+	if err := lm.WriteLog(LevelInfo, "msg 1"); err == nil {
+		t.Error("Expected the first failure to be returned")
+	}
+	if err := lm.WriteLog(LevelInfo, "msg 2"); err != nil {
+		t.Errorf("Expected entry to be parked when the breaker opens: %v", err)
+	}
+	attempts := fb.attempts
+	if err := lm.WriteLog(LevelInfo, "msg 3"); err != nil {
+		t.Errorf("Expected entry to be parked: %v", err)
+	}
+	if fb.attempts != attempts {
+		t.Error("Backend should not be called while the breaker is open")
+	}
+
+	fb.setFailing(false)
+	time.Sleep(40 * time.Millisecond)
+	if err := lm.WriteLog(LevelInfo, "msg 4"); err != nil {
+		t.Fatalf("Expected probe write to succeed: %v", err)
+	}
+
+	if fmt.Sprint(fb.written) != "[msg 2 msg 3 msg 4]" {
+		t.Errorf("Expected parked entries to be flushed in order, got %v", fb.written)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(transitions) != "[closed->open open->half-open half-open->closed]" {
+		t.Errorf("Unexpected transitions %v", transitions)
+	}
+}
+
+func TestCloseReportsUnflushedParkedEntries(t *testing.T) {
+	lm, fb := withFlakyBackend(t, Config{CircuitBreaker: BreakerConfig{FailureThreshold: 1}})
+
+	fb.setFailing(true)
+	lm.WriteLog(LevelInfo, "msg 1")
+	lm.WriteLog(LevelWarn, "msg 2") // parked
+
+	err := lm.Close()
+	if err == nil || !strings.Contains(err.Error(), "failed to flush parked logs: backend down") {
+		t.Errorf("Close = %v, want the flush error", err)
+	}
+	if dropped := lm.Stats().Dropped; dropped[LevelWarn] != 1 {
+		t.Errorf("dropped = %v", dropped)
+	}
+}
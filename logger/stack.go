@@ -0,0 +1,125 @@
+// /logger/stack.go
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+)
+
+// StackKey is the metadata key holding the []StackFrame captured for entries
+// at or above Config.StackTraceLevel
+const StackKey = "stack"
+
+// maxStackDepth limits the frames captured per entry
+const maxStackDepth = 32
+
+// StackFrame is one frame of a captured stack, innermost first
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// ErrorInfo is an error value expanded into its cause chain. Errors wrapping
+// one error have a single cause, errors.Join and similar have several.
+type ErrorInfo struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Causes  []ErrorInfo `json:"causes,omitempty"`
+}
+
+// withStack returns a copy of metadata with the stack from skip frames up
+// added under StackKey
+func withStack(metadata map[string]interface{}, skip int) map[string]interface{} {
+	out := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		out[k] = v
+	}
+	if _, ok := out[StackKey]; !ok {
+		out[StackKey] = captureStack(skip)
+	}
+	return out
+}
+
+// expandErrors replaces error values by their ErrorInfo, which unlike most
+// errors survives encoding as JSON. The map is copied only if it holds one.
+func expandErrors(metadata map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	for k, v := range metadata {
+		err, ok := v.(error)
+		if !ok || err == nil {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(metadata))
+			for k, v := range metadata {
+				out[k] = v
+			}
+		}
+		out[k] = expandError(err)
+	}
+	if out == nil {
+		return metadata
+	}
+	return out
+}
+
+// captureStack returns the stack with skip frames removed, counted as
+// runtime.Callers would from the caller of captureStack
+func captureStack(skip int) []StackFrame {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := make([]StackFrame, 0, n)
+	for more := n > 0; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+	}
+	return stack
+}
+
+// expandError walks the errors wrapped by err via Unwrap() error and
+// Unwrap() []error
+func expandError(err error) ErrorInfo {
+	info := ErrorInfo{Message: err.Error(), Type: fmt.Sprintf("%T", err)}
+
+	var causes []error
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			causes = []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		causes = u.Unwrap()
+	}
+	for _, cause := range causes {
+		if cause != nil {
+			info.Causes = append(info.Causes, expandError(cause))
+		}
+	}
+	return info
+}
+
+// StackFrames returns the stack stored in an entry, both as captured and as
+// decoded from a backend that stores metadata as JSON
+func StackFrames(entry LogEntry) []StackFrame {
+	switch v := entry.Metadata[StackKey].(type) {
+	case []StackFrame:
+		return v
+	case []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var stack []StackFrame
+		if err := json.Unmarshal(data, &stack); err != nil {
+			return nil
+		}
+		return stack
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// /logger/stack_test.go
+
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStackTrace(t *testing.T) {
+	lm := newTestManager(t, Config{StackTraceLevel: LevelError})
+
+	line := currentLine() + 1
+	lm.WriteLog(LevelError, "failed")
+	lm.WriteLog(LevelWarn, "careful")
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	stack := StackFrames(entries[0])
+	if len(stack) == 0 {
+		t.Fatalf("no stack in %v", entries[0].Metadata)
+	}
+	if !strings.HasSuffix(stack[0].Function, ".TestStackTrace") || stack[0].Line != line ||
+		!strings.HasSuffix(stack[0].File, "stack_test.go") {
+		t.Errorf("top frame = %+v, want line %d of TestStackTrace", stack[0], line)
+	}
+	if entries[1].Metadata != nil {
+		t.Errorf("unexpected metadata below the stack level: %v", entries[1].Metadata)
+	}
+}
+
+func TestStackTraceDefault(t *testing.T) {
+	lm := newTestManager(t, Config{})
+	off := newTestManager(t, Config{DisableStackTraces: true})
+
+	for _, m := range []LogManager{lm, off} {
+		m.WriteLog(LevelWarn, "careful")
+		m.WriteLog(LevelError, "failed")
+	}
+
+	entries, _ := lm.ReadLogs("", LogFilter{})
+	if StackFrames(entries[0]) != nil || StackFrames(entries[1]) == nil {
+		t.Errorf("expected a stack on ERROR only by default: %v", entries)
+	}
+	entries, _ = off.ReadLogs("", LogFilter{})
+	if entries[1].Metadata != nil {
+		t.Errorf("unexpected metadata with stack traces disabled: %v", entries[1].Metadata)
+	}
+}
+
+func TestExpandError(t *testing.T) {
+	base := io.ErrUnexpectedEOF
+	wrapped := fmt.Errorf("read config: %w", base)
+	joined := errors.Join(wrapped, os.ErrNotExist)
+
+	got := expandError(joined)
+	want := ErrorInfo{
+		Message: joined.Error(),
+		Type:    "*errors.joinError",
+		Causes: []ErrorInfo{
+			{Message: wrapped.Error(), Type: "*fmt.wrapError", Causes: []ErrorInfo{
+				{Message: base.Error(), Type: "*errors.errorString"},
+			}},
+			{Message: os.ErrNotExist.Error(), Type: "*errors.errorString"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandError =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestStackTracePersisted(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	lm, err := NewLogManager(Config{
+		Backend:         BackendFile,
+		BackendConfig:   FileConfig{FilePath: path},
+		StackTraceLevel: LevelError,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	cause := fmt.Errorf("dial db1: %w", os.ErrDeadlineExceeded)
+	lm.WriteLogWithMetadata(LevelError, "query failed", map[string]interface{}{"error": cause})
+	// Below the stack level errors are expanded too, just without a stack
+	lm.WriteLogWithMetadata(LevelWarn, "retrying", map[string]interface{}{"error": errors.New("boom")})
+
+	data, _ := os.ReadFile(path)
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Fatalf("expected 2 lines, got %d:\n%s", n, data)
+	}
+
+	entries, err := lm.ReadLogs("", LogFilter{})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ReadLogs = %v, %v", entries, err)
+	}
+	if stack := StackFrames(entries[0]); len(stack) == 0 || stack[0].Line == 0 {
+		t.Errorf("stack not read back: %v", entries[0].Metadata[StackKey])
+	}
+	info, _ := entries[0].Metadata["error"].(map[string]interface{})
+	causes, _ := info["causes"].([]interface{})
+	if info["message"] != cause.Error() || len(causes) != 1 {
+		t.Errorf("error not expanded: %v", entries[0].Metadata["error"])
+	}
+	if info, _ := entries[1].Metadata["error"].(map[string]interface{}); info["message"] != "boom" ||
+		StackFrames(entries[1]) != nil {
+		t.Errorf("WARN metadata = %v", entries[1].Metadata)
+	}
+}
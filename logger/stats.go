@@ -0,0 +1,260 @@
+// /logger/stats.go
+
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the backend write latency histogram
+var latencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// LatencyHistogram counts observations per bucket. Counts[i] holds the
+// observations <= Buckets[i] and above the previous bound; the last element
+// of Counts holds those above every bound.
+type LatencyHistogram struct {
+	Buckets []time.Duration
+	Counts  []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// LogStats is a snapshot of LogManager runtime statistics
+type LogStats struct {
+	Accepted map[LogLevel]uint64 // entries accepted by WriteLog
+	Written  map[LogLevel]uint64 // entries written to the backend
+	Dropped  map[LogLevel]uint64 // entries lost: queue full, failed writes or flight recorder
+
+	IsAsync       bool
+	QueueLength   int
+	QueueCapacity int
+
+	HandlerCount  int
+	HandlerErrors uint64
+	Subscribers   int
+
+	WriteLatency LatencyHistogram // per backend write attempt
+
+	BreakerState BreakerState
+	Parked       int
+
+	FlightRecorded int // entries held by the flight recorder
+
+	LastError     string
+	LastErrorTime time.Time
+
+	BytesWritten   uint64 // 0 unless the backend implements ByteCounter
+	MalformedLines uint64 // 0 unless the backend implements MalformedLineCounter
+}
+
+// HealthStatus reports whether logging currently works
+type HealthStatus struct {
+	Healthy          bool
+	BackendReachable bool
+	BreakerState     BreakerState
+	Error            string
+	CheckedAt        time.Time
+}
+
+// HealthChecker is implemented by backends that can verify their storage
+// is reachable
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// ByteCounter is implemented by backends that count the bytes they write
+type ByteCounter interface {
+	BytesWritten() uint64
+}
+
+// MalformedLineCounter is implemented by backends that count the stored
+// lines their reads could not decode and skipped
+type MalformedLineCounter interface {
+	MalformedLines() uint64
+}
+
+// managerStats holds the counters behind Stats
+type managerStats struct {
+	mu            sync.Mutex
+	accepted      map[LogLevel]uint64
+	written       map[LogLevel]uint64
+	dropped       map[LogLevel]uint64
+	handlerErrors uint64
+	latency       []uint64
+	latencyCount  uint64
+	latencySum    time.Duration
+	lastError     string
+	lastErrorTime time.Time
+}
+
+func newManagerStats() *managerStats {
+	return &managerStats{
+		accepted: make(map[LogLevel]uint64),
+		written:  make(map[LogLevel]uint64),
+		dropped:  make(map[LogLevel]uint64),
+		latency:  make([]uint64, len(latencyBuckets)+1),
+	}
+}
+
+func (s *managerStats) accept(level LogLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accepted[level]++
+}
+
+func (s *managerStats) drop(level LogLevel, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropped[level]++
+	s.setError(err)
+}
+
+func (s *managerStats) write(level LogLevel, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := 0
+	for i < len(latencyBuckets) && d > latencyBuckets[i] {
+		i++
+	}
+	s.latency[i]++
+	s.latencyCount++
+	s.latencySum += d
+
+	if err != nil {
+		s.setError(err)
+		return
+	}
+	s.written[level]++
+}
+
+func (s *managerStats) handlerError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlerErrors++
+	s.setError(err)
+}
+
+// setError must be called with s.mu held
+func (s *managerStats) setError(err error) {
+	if err != nil {
+		s.lastError = err.Error()
+		s.lastErrorTime = time.Now()
+	}
+}
+
+func copyCounts(m map[LogLevel]uint64) map[LogLevel]uint64 {
+	out := make(map[LogLevel]uint64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// timedWrite writes entry to the backend, recording latency and the outcome
+func (lm *logManagerImpl) timedWrite(entry LogEntry) error {
+	start := time.Now()
+	err := lm.backend.Write(entry)
+	lm.stats.write(entry.Level, time.Since(start), err)
+	return err
+}
+
+func (lm *logManagerImpl) Stats() LogStats {
+	s := lm.stats
+	s.mu.Lock()
+	stats := LogStats{
+		Accepted:      copyCounts(s.accepted),
+		Written:       copyCounts(s.written),
+		Dropped:       copyCounts(s.dropped),
+		HandlerErrors: s.handlerErrors,
+		WriteLatency: LatencyHistogram{
+			Buckets: append([]time.Duration(nil), latencyBuckets...),
+			Counts:  append([]uint64(nil), s.latency...),
+			Count:   s.latencyCount,
+			Sum:     s.latencySum,
+		},
+		LastError:     s.lastError,
+		LastErrorTime: s.lastErrorTime,
+	}
+	s.mu.Unlock()
+
+	stats.IsAsync = lm.isAsync
+	if lm.isAsync {
+		stats.QueueLength = len(lm.logChannel)
+		stats.QueueCapacity = cap(lm.logChannel)
+	}
+	stats.HandlerCount = len(lm.snapshotHandlers())
+
+	lm.subMu.Lock()
+	stats.Subscribers = len(lm.subscribers)
+	lm.subMu.Unlock()
+
+	if lm.breaker != nil {
+		stats.BreakerState = lm.breaker.currentState()
+		lm.parkMu.Lock()
+		stats.Parked = len(lm.parked)
+		lm.parkMu.Unlock()
+	}
+
+	if lm.recorder != nil {
+		stats.FlightRecorded = lm.recorder.buffered()
+	}
+
+	if bc, ok := lm.backend.(ByteCounter); ok {
+		stats.BytesWritten = bc.BytesWritten()
+	}
+	if mc, ok := lm.backend.(MalformedLineCounter); ok {
+		stats.MalformedLines = mc.MalformedLines()
+	}
+
+	return stats
+}
+
+// Health checks the backend (when it implements HealthChecker) and the
+// circuit breaker. Backends without a check are assumed reachable.
+func (lm *logManagerImpl) Health(ctx context.Context) HealthStatus {
+	status := HealthStatus{CheckedAt: time.Now()}
+
+	select {
+	case <-lm.closed:
+		status.Error = "log manager is closed"
+		return status
+	default:
+	}
+
+	if lm.backend == nil {
+		status.Error = "backend not initialized"
+		return status
+	}
+
+	status.BackendReachable = true
+	if hc, ok := lm.backend.(HealthChecker); ok {
+		if err := hc.Ping(ctx); err != nil {
+			status.BackendReachable = false
+			status.Error = err.Error()
+		}
+	}
+
+	if lm.breaker != nil {
+		status.BreakerState = lm.breaker.currentState()
+	}
+
+	status.Healthy = status.BackendReachable && status.BreakerState != BreakerOpen
+	if status.Healthy || status.Error != "" {
+		return status
+	}
+	status.Error = "backend circuit breaker is open"
+	return status
+}
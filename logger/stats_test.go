@@ -0,0 +1,81 @@
+// /logger/stats_test.go
+
+package logger
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestStatsCounts(t *testing.T) {
+	lm, fb := withFlakyBackend(t, Config{})
+
+	lm.WriteLog(LevelInfo, "one")
+	lm.WriteLog(LevelInfo, "two")
+	fb.setFailing(true)
+	if err := lm.WriteLog(LevelError, "lost"); err == nil {
+		t.Fatal("expected write error")
+	}
+
+	stats := lm.Stats()
+	if stats.Accepted[LevelInfo] != 2 || stats.Accepted[LevelError] != 1 {
+		t.Errorf("accepted = %v", stats.Accepted)
+	}
+	if stats.Written[LevelInfo] != 2 || stats.Written[LevelError] != 0 {
+		t.Errorf("written = %v", stats.Written)
+	}
+	if stats.Dropped[LevelError] != 1 {
+		t.Errorf("dropped = %v", stats.Dropped)
+	}
+	if stats.WriteLatency.Count != 3 {
+		t.Errorf("latency count = %d, want 3", stats.WriteLatency.Count)
+	}
+	var sum uint64
+	for _, c := range stats.WriteLatency.Counts {
+		sum += c
+	}
+	if sum != 3 || len(stats.WriteLatency.Counts) != len(stats.WriteLatency.Buckets)+1 {
+		t.Errorf("latency counts = %v", stats.WriteLatency.Counts)
+	}
+	if stats.LastError != "backend down" || stats.LastErrorTime.IsZero() {
+		t.Errorf("last error = %q at %v", stats.LastError, stats.LastErrorTime)
+	}
+}
+
+func TestStatsHandlerErrors(t *testing.T) {
+	lm := newTestManager(t, Config{})
+	impl := lm.(*logManagerImpl)
+
+	lm.RegisterLogHandler(&flakyHandler{})
+	lm.WriteLog(LevelInfo, "fail")
+	impl.waitHandlersIdle(nil)
+
+	stats := lm.Stats()
+	if stats.HandlerCount != 1 || stats.HandlerErrors != 1 {
+		t.Errorf("handlers = %d, errors = %d", stats.HandlerCount, stats.HandlerErrors)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	path := t.TempDir() + "/app.log"
+	lm, err := NewLogManager(Config{Backend: BackendFile, BackendConfig: FileConfig{FilePath: path}})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+	defer lm.Close()
+
+	if h := lm.Health(context.Background()); !h.Healthy || !h.BackendReachable {
+		t.Fatalf("expected healthy, got %+v", h)
+	}
+
+	os.Remove(path)
+	if h := lm.Health(context.Background()); h.Healthy || h.BackendReachable || h.Error == "" {
+		t.Fatalf("expected unreachable after removing the file, got %+v", h)
+	}
+
+	lm.Close()
+	if h := lm.Health(context.Background()); h.Healthy {
+		t.Fatalf("expected unhealthy after Close, got %+v", h)
+	}
+}
@@ -0,0 +1,211 @@
+// /logger/subscription.go
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MetaDropped is set on the synthetic WARN entry a subscriber receives after
+// entries were dropped because its buffer was full. The value is the number
+// of dropped entries.
+const MetaDropped = "subscription.dropped"
+
+// subscriberBufferSize bounds the entries queued for a single subscriber
+const subscriberBufferSize = 256
+
+type subscriber struct {
+	matcher *entryMatcher
+	ch      chan LogEntry
+	dropped int64
+
+	// While holding, entries are collected in held instead of ch so that
+	// Tail can deliver the history first
+	holding bool
+	held    []LogEntry
+}
+
+// Subscribe delivers new entries matching filter until ctx is cancelled or
+// the LogManager is closed, after which the channel is closed. Paging fields
+// of the filter are ignored. A slow reader does not block logging: entries
+// that do not fit in its buffer are dropped and reported by an entry carrying
+// MetaDropped.
+func (lm *logManagerImpl) Subscribe(ctx context.Context, filter LogFilter) (<-chan LogEntry, error) {
+	sub, err := lm.subscribe(filter, subscriberBufferSize, false)
+	if err != nil {
+		return nil, err
+	}
+	lm.unsubscribeOnDone(ctx, sub)
+	return sub.ch, nil
+}
+
+// unsubscribeOnDone removes sub once ctx is cancelled
+func (lm *logManagerImpl) unsubscribeOnDone(ctx context.Context, sub *subscriber) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			lm.unsubscribe(sub)
+		case <-lm.closed:
+		}
+	}()
+}
+
+// Tail delivers the last n entries matching filter, oldest first, and then
+// keeps following new matching entries like Subscribe. Writes are only held
+// back while the newest matching entry is read; it marks the boundary from
+// which older entries are paged and after which live entries are delivered,
+// so no entry is missed or repeated at the switch.
+func (lm *logManagerImpl) Tail(ctx context.Context, n int, filter LogFilter) (<-chan LogEntry, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid tail size: %d", n)
+	}
+
+	filter.Limit, filter.Order, filter.Cursor = 0, "", ""
+
+	lm.writeMu.Lock()
+	var newest LogPage
+	if n > 0 {
+		last := filter
+		last.Limit, last.Order = 1, OrderDesc
+		page, err := lm.ReadLogsPage("", last)
+		if err != nil {
+			lm.writeMu.Unlock()
+			return nil, err
+		}
+		newest = page
+	}
+	sub, err := lm.subscribe(filter, n+subscriberBufferSize, true)
+	lm.writeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	history := newest.Entries
+	if n > 1 && newest.NextCursor != "" {
+		older := filter
+		older.Limit, older.Order, older.Cursor = n-1, OrderDesc, newest.NextCursor
+		page, err := lm.ReadLogsPage("", older)
+		if err != nil {
+			lm.unsubscribe(sub)
+			return nil, err
+		}
+		history = append(history, page.Entries...)
+	}
+	lm.release(sub, history)
+
+	lm.unsubscribeOnDone(ctx, sub)
+	return sub.ch, nil
+}
+
+// release delivers history, newest first in the slice, then the entries held
+// back while it was read, and switches sub to direct delivery
+func (lm *logManagerImpl) release(sub *subscriber, history []LogEntry) {
+	lm.subMu.Lock()
+	defer lm.subMu.Unlock()
+
+	if _, ok := lm.subscribers[sub]; !ok {
+		return // closed meanwhile
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		sub.ch <- history[i]
+	}
+	held := sub.held
+	sub.holding, sub.held = false, nil
+	for _, entry := range held {
+		sub.deliver(entry)
+	}
+}
+
+// subscribe registers a subscriber with the given buffer size; a holding
+// subscriber collects entries until release
+func (lm *logManagerImpl) subscribe(filter LogFilter, size int, holding bool) (*subscriber, error) {
+	m, err := newEntryMatcher("", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	lm.subMu.Lock()
+	defer lm.subMu.Unlock()
+
+	select {
+	case <-lm.closed:
+		return nil, errors.New("log manager is closed")
+	default:
+	}
+
+	sub := &subscriber{matcher: m, ch: make(chan LogEntry, size), holding: holding}
+	if lm.subscribers == nil {
+		lm.subscribers = make(map[*subscriber]struct{})
+	}
+	lm.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (lm *logManagerImpl) unsubscribe(sub *subscriber) {
+	lm.subMu.Lock()
+	defer lm.subMu.Unlock()
+
+	if _, ok := lm.subscribers[sub]; ok {
+		delete(lm.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// publish delivers entry to every matching subscriber without blocking
+func (lm *logManagerImpl) publish(entry LogEntry) {
+	lm.subMu.Lock()
+	defer lm.subMu.Unlock()
+
+	for sub := range lm.subscribers {
+		if !sub.matcher.match(entry) {
+			continue
+		}
+		if !sub.holding {
+			sub.deliver(entry)
+		} else if len(sub.held) < subscriberBufferSize {
+			sub.held = append(sub.held, entry)
+		} else {
+			sub.dropped++
+		}
+	}
+}
+
+// deliver queues entry without blocking, preceded by a lag report when
+// entries were dropped before; subMu must be held
+func (sub *subscriber) deliver(entry LogEntry) {
+	if sub.dropped > 0 {
+		lag := LogEntry{
+			Level:     LevelWarn,
+			Message:   fmt.Sprintf("subscriber lagging: %d entries dropped", sub.dropped),
+			Timestamp: time.Now(),
+			Metadata:  map[string]interface{}{MetaDropped: sub.dropped},
+		}
+		select {
+		case sub.ch <- lag:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+			return
+		}
+	}
+
+	select {
+	case sub.ch <- entry:
+	default:
+		sub.dropped++
+	}
+}
+
+// closeSubscribers closes every subscriber channel on shutdown
+func (lm *logManagerImpl) closeSubscribers() {
+	lm.subMu.Lock()
+	defer lm.subMu.Unlock()
+
+	for sub := range lm.subscribers {
+		close(sub.ch)
+	}
+	lm.subscribers = nil
+}
@@ -0,0 +1,222 @@
+// /logger/subscription_test.go
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, ch <-chan LogEntry) LogEntry {
+	t.Helper()
+	select {
+	case entry, ok := <-ch:
+		if !ok {
+			t.Fatal("Subscription channel closed unexpectedly")
+		}
+		return entry
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for entry")
+	}
+	return LogEntry{}
+}
+
+func TestSubscribeFilter(t *testing.T) {
+	lm := newTestManager(t, Config{Async: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := lm.Subscribe(ctx, LogFilter{MinLevel: LevelError})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	lm.WriteLog(LevelInfo, "ignored")
+	lm.WriteLog(LevelError, "delivered")
+
+	if entry := receive(t, ch); entry.Message != "delivered" {
+		t.Errorf("Expected the ERROR entry, got %q", entry.Message)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("Expected channel to be closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Error("Channel was not closed after cancel")
+	}
+}
+
+func TestSubscribeOverflow(t *testing.T) {
+	lm := newTestManager(t, Config{})
+
+	ch, err := lm.Subscribe(context.Background(), LogFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	for i := 0; i < subscriberBufferSize+10; i++ {
+		lm.WriteLog(LevelInfo, fmt.Sprintf("msg %d", i))
+	}
+	for i := 0; i < subscriberBufferSize; i++ {
+		receive(t, ch)
+	}
+
+	lm.WriteLog(LevelInfo, "after lag")
+
+	lag := receive(t, ch)
+	if lag.Metadata[MetaDropped] != int64(10) {
+		t.Errorf("Expected lag entry reporting 10 drops, got %+v", lag)
+	}
+	if entry := receive(t, ch); entry.Message != "after lag" {
+		t.Errorf("Expected delivery to resume, got %q", entry.Message)
+	}
+}
+
+func TestSubscribeClosedOnClose(t *testing.T) {
+	lm := newTestManager(t, Config{})
+
+	ch, err := lm.Subscribe(context.Background(), LogFilter{})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	lm.Close()
+
+	if _, ok := <-ch; ok {
+		t.Error("Expected channel to be closed by Close")
+	}
+	if _, err := lm.Subscribe(context.Background(), LogFilter{}); err == nil {
+		t.Error("Expected Subscribe to fail after Close")
+	}
+}
+
+func TestTailHistoryThenLive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lm, err := NewLogManager(Config{
+		Backend:       BackendFile,
+		BackendConfig: FileConfig{FilePath: path},
+		Async:         true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create log manager: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		lm.WriteLog(LevelInfo, fmt.Sprintf("old %d", i))
+	}
+	lm.WriteLog(LevelDebug, "filtered")
+
+	// Keep writing while the tail starts to exercise the switchover
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if lm.WriteLog(LevelInfo, fmt.Sprintf("live %d", i)) != nil {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	ch, err := lm.Tail(context.Background(), 3, LogFilter{MinLevel: LevelInfo})
+	if err != nil {
+		t.Fatalf("Tail failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	<-done
+	lm.Close()
+
+	var got []string
+	for entry := range ch {
+		got = append(got, entry.Message)
+	}
+	if len(got) < 3 {
+		t.Fatalf("Expected at least the 3 history entries, got %v", got)
+	}
+
+	// The stream must be a gap-free, duplicate-free suffix of everything written
+	fb := &FileBackend{}
+	if err := fb.Init(FileConfig{FilePath: path}); err != nil {
+		t.Fatalf("Failed to reopen log file: %v", err)
+	}
+	defer fb.Close()
+
+	all, err := fb.Read("", LogFilter{MinLevel: LevelInfo})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := messages(all[len(all)-len(got):])
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Tail stream is not a contiguous suffix:\n got: %v\nwant: %v", got, want)
+	}
+}
+
+// blockingPageBackend blocks paged reads with a cursor until release is closed
+type blockingPageBackend struct {
+	*MemoryBackend
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingPageBackend) ReadPage(level LogLevel, filter LogFilter) (LogPage, error) {
+	if filter.Cursor != "" {
+		close(b.reading)
+		<-b.release
+	}
+	return b.MemoryBackend.ReadPage(level, filter)
+}
+
+func TestTailDoesNotBlockWrites(t *testing.T) {
+	lm := newTestManager(t, Config{})
+	impl := lm.(*logManagerImpl)
+	backend := &blockingPageBackend{MemoryBackend: impl.backend.(*MemoryBackend),
+		reading: make(chan struct{}), release: make(chan struct{})}
+	impl.backend = backend
+
+	for i := 0; i < 5; i++ {
+		lm.WriteLog(LevelInfo, fmt.Sprintf("old %d", i))
+	}
+
+	tailed := make(chan (<-chan LogEntry), 1)
+	go func() {
+		ch, err := lm.Tail(context.Background(), 3, LogFilter{})
+		if err != nil {
+			t.Errorf("Tail failed: %v", err)
+		}
+		tailed <- ch
+	}()
+
+	<-backend.reading
+	written := make(chan struct{})
+	go func() {
+		lm.WriteLog(LevelInfo, "live")
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("WriteLog blocked while Tail read the history")
+	}
+	close(backend.release)
+
+	ch := <-tailed
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, receive(t, ch).Message)
+	}
+	if fmt.Sprint(got) != "[old 2 old 3 old 4 live]" {
+		t.Errorf("Tail delivered %v", got)
+	}
+}
@@ -0,0 +1,235 @@
+// /logger/webhook.go
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultWebhookTemplate renders the batch as a JSON object
+const DefaultWebhookTemplate = `{"count":{{.Count}},"entries":{{json .Entries}}}`
+
+// DefaultSignatureHeader carries "sha256=<hex HMAC of the body>"
+const DefaultSignatureHeader = "X-Signature-256"
+
+const defaultWebhookTimeout = 4 * time.Second
+
+// WebhookConfig configures a WebhookHandler
+type WebhookConfig struct {
+	URL         string
+	Method      string            // default POST
+	Template    string            // text/template over WebhookPayload, default DefaultWebhookTemplate
+	ContentType string            // default application/json
+	Headers     map[string]string // added to every request
+
+	BatchWindow time.Duration // collect entries for this long before sending; 0 sends each entry
+	MaxBatch    int           // send early once this many entries are collected, default 100
+
+	// Retry retries 5xx, 429 and network errors. Timeout bounds sending one
+	// batch including retries and waiting for the previous batch; the default
+	// stays below the default Config.HandlerTimeout, and a lower
+	// HandlerTimeout needs a lower Timeout too.
+	Retry   RetryPolicy
+	Timeout time.Duration // default 4s
+	Client  *http.Client  // default http.DefaultClient
+
+	Secret          string // HMAC-SHA256 key; requests are unsigned when empty
+	SignatureHeader string // default DefaultSignatureHeader
+
+	OnError func(err error) // failures of batches sent in the background, default written to stderr
+}
+
+// WebhookPayload is the data passed to the template
+type WebhookPayload struct {
+	Entries []LogEntry
+	Count   int
+}
+
+// WebhookHandler is a LogHandler posting entries to an HTTP endpoint.
+// Register it with WithMinLevel or WithFilter to choose what is sent.
+type WebhookHandler struct {
+	config WebhookConfig
+	tmpl   *template.Template
+
+	mu    sync.Mutex
+	batch []LogEntry
+	timer *time.Timer
+
+	sending chan struct{} // held while a batch is sent, keeps batches in order
+}
+
+// webhookFuncs are available in payload templates
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"time": func(t time.Time, layout string) string {
+		return t.Format(layout)
+	},
+}
+
+// NewWebhookHandler validates config and parses the payload template
+func NewWebhookHandler(config WebhookConfig) (*WebhookHandler, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.Template == "" {
+		config.Template = DefaultWebhookTemplate
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+	if config.MaxBatch <= 0 {
+		config.MaxBatch = 100
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultWebhookTimeout
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = DefaultSignatureHeader
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "webhook error: %v\n", err)
+		}
+	}
+
+	tmpl, err := template.New("webhook").Funcs(webhookFuncs).Parse(config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	return &WebhookHandler{config: config, tmpl: tmpl, sending: make(chan struct{}, 1)}, nil
+}
+
+// Handle implements LogHandler. Without a batch window the entry is sent
+// right away and the error returned; otherwise it is queued.
+func (h *WebhookHandler) Handle(entry LogEntry) error {
+	if h.config.BatchWindow <= 0 {
+		return h.send([]LogEntry{entry})
+	}
+
+	h.mu.Lock()
+	h.batch = append(h.batch, entry)
+	if len(h.batch) >= h.config.MaxBatch {
+		batch := h.takeBatch()
+		h.mu.Unlock()
+		return h.send(batch)
+	}
+	if h.timer == nil {
+		h.timer = time.AfterFunc(h.config.BatchWindow, func() {
+			if err := h.Flush(); err != nil {
+				h.config.OnError(err)
+			}
+		})
+	}
+	h.mu.Unlock()
+	return nil
+}
+
+// Flush sends the queued entries now
+func (h *WebhookHandler) Flush() error {
+	h.mu.Lock()
+	batch := h.takeBatch()
+	h.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return h.send(batch)
+}
+
+// Close sends the queued entries; call it after the LogManager is closed
+func (h *WebhookHandler) Close() error {
+	return h.Flush()
+}
+
+// takeBatch must be called with h.mu held
+func (h *WebhookHandler) takeBatch() []LogEntry {
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	batch := h.batch
+	h.batch = nil
+	return batch
+}
+
+// send renders and posts one batch with retries within the timeout
+func (h *WebhookHandler) send(batch []LogEntry) error {
+	var body bytes.Buffer
+	if err := h.tmpl.Execute(&body, WebhookPayload{Entries: batch, Count: len(batch)}); err != nil {
+		return fmt.Errorf("failed to render webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+
+	select {
+	case h.sending <- struct{}{}:
+		defer func() { <-h.sending }()
+	case <-ctx.Done():
+		return fmt.Errorf("webhook batch of %d entries not sent: previous batch still sending", len(batch))
+	}
+
+	return h.config.Retry.doContext(ctx, func() error {
+		return h.post(ctx, body.Bytes())
+	})
+}
+
+func (h *WebhookHandler) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, h.config.Method, h.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{fmt.Errorf("failed to create webhook request: %w", err)}
+	}
+	req.Header.Set("Content-Type", h.config.ContentType)
+	for k, v := range h.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if h.config.Secret != "" {
+		req.Header.Set(h.config.SignatureHeader, SignWebhook([]byte(h.config.Secret), body))
+	}
+
+	resp, err := h.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+	return &permanentError{err}
+}
+
+// SignWebhook returns the signature header value for body, for use by
+// receivers verifying requests
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,220 @@
+// /logger/webhook_test.go
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []string
+	sigs     []string
+	statuses []int // returned in order, then 200
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bodies = append(s.bodies, string(body))
+		s.sigs = append(s.sigs, r.Header.Get(DefaultSignatureHeader))
+		if len(s.statuses) > 0 {
+			w.WriteHeader(s.statuses[0])
+			s.statuses = s.statuses[1:]
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func TestWebhookTemplateAndSignature(t *testing.T) {
+	srv := newWebhookServer(t)
+	h, err := NewWebhookHandler(WebhookConfig{
+		URL:      srv.URL,
+		Template: `{"text": "{{range .Entries}}{{.Level}} {{.Message}} {{index .Metadata "host"}}{{end}}"}`,
+		Secret:   "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookHandler failed: %v", err)
+	}
+
+	if err := h.Handle(LogEntry{Level: LevelError, Message: "disk full", Metadata: map[string]interface{}{"host": "db1"}}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	reqs := srv.requests()
+	if len(reqs) != 1 || reqs[0] != `{"text": "ERROR disk full db1"}` {
+		t.Fatalf("requests = %q", reqs)
+	}
+	if want := SignWebhook([]byte("s3cret"), []byte(reqs[0])); srv.sigs[0] != want {
+		t.Errorf("signature = %q, want %q", srv.sigs[0], want)
+	}
+}
+
+func TestWebhookBatching(t *testing.T) {
+	srv := newWebhookServer(t)
+	h, err := NewWebhookHandler(WebhookConfig{URL: srv.URL, BatchWindow: time.Hour, MaxBatch: 3})
+	if err != nil {
+		t.Fatalf("NewWebhookHandler failed: %v", err)
+	}
+
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		if err := h.Handle(LogEntry{Level: LevelError, Message: msg}); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+	if reqs := srv.requests(); len(reqs) != 1 {
+		t.Fatalf("expected one full batch, got %d requests", len(reqs))
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reqs := srv.requests()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	var payload struct {
+		Count   int        `json:"count"`
+		Entries []LogEntry `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(reqs[1]), &payload); err != nil {
+		t.Fatalf("invalid default payload %q: %v", reqs[1], err)
+	}
+	if payload.Count != 1 || payload.Entries[0].Message != "d" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookBatchWindow(t *testing.T) {
+	srv := newWebhookServer(t)
+	h, _ := NewWebhookHandler(WebhookConfig{URL: srv.URL, BatchWindow: 20 * time.Millisecond})
+
+	h.Handle(LogEntry{Level: LevelError, Message: "a"})
+	h.Handle(LogEntry{Level: LevelError, Message: "b"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(srv.requests()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if reqs := srv.requests(); len(reqs) != 1 {
+		t.Fatalf("expected one batch after the window, got %d", len(reqs))
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	srv := newWebhookServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	h, _ := NewWebhookHandler(WebhookConfig{URL: srv.URL, Retry: retry})
+	if err := h.Handle(LogEntry{Level: LevelError, Message: "x"}); err != nil {
+		t.Fatalf("expected success after retries: %v", err)
+	}
+	if n := len(srv.requests()); n != 3 {
+		t.Errorf("attempts = %d, want 3", n)
+	}
+
+	// Client errors are not retried
+	srv = newWebhookServer(t, http.StatusBadRequest)
+	h, _ = NewWebhookHandler(WebhookConfig{URL: srv.URL, Retry: retry})
+	if err := h.Handle(LogEntry{Level: LevelError, Message: "x"}); err == nil {
+		t.Fatal("expected error for 400")
+	}
+	if n := len(srv.requests()); n != 1 {
+		t.Errorf("attempts = %d, want 1", n)
+	}
+}
+
+func TestWebhookInvalidConfig(t *testing.T) {
+	if _, err := NewWebhookHandler(WebhookConfig{}); err == nil {
+		t.Error("expected error without URL")
+	}
+	if _, err := NewWebhookHandler(WebhookConfig{URL: "http://x", Template: "{{"}); err == nil {
+		t.Error("expected error for invalid template")
+	}
+}
+
+func TestWebhookOnLogManager(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+	lm := newTestManager(t, Config{OnHandlerError: func(id HandlerID, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	}})
+
+	srv := newWebhookServer(t, http.StatusServiceUnavailable)
+	h, _ := NewWebhookHandler(WebhookConfig{URL: srv.URL, Retry: RetryPolicy{MaxAttempts: 2}})
+	if h.config.Timeout >= defaultHandlerTimeout {
+		t.Errorf("default webhook timeout %s does not fit in the handler timeout %s", h.config.Timeout, defaultHandlerTimeout)
+	}
+	lm.RegisterLogHandler(h, WithMinLevel(LevelError))
+
+	lm.WriteLog(LevelInfo, "ignored")
+	lm.WriteLog(LevelError, "disk full")
+	lm.Close()
+
+	if reqs := srv.requests(); len(reqs) != 2 {
+		t.Errorf("requests = %q, want a retried delivery", reqs)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 0 {
+		t.Errorf("reported = %v", reported)
+	}
+}
+
+func TestWebhookTimeoutIncludesRetries(t *testing.T) {
+	var mu sync.Mutex
+	var reported []error
+	lm := newTestManager(t, Config{
+		HandlerTimeout: time.Second,
+		OnHandlerError: func(id HandlerID, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, err)
+		},
+	})
+
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body) // the disconnect is only noticed after the body
+		<-r.Context().Done()
+	}))
+	defer hung.Close()
+	h, _ := NewWebhookHandler(WebhookConfig{
+		URL:     hung.URL,
+		Timeout: 100 * time.Millisecond,
+		Retry:   RetryPolicy{MaxAttempts: 10, InitialBackoff: 50 * time.Millisecond},
+	})
+	lm.RegisterLogHandler(h)
+
+	start := time.Now()
+	lm.WriteLog(LevelError, "disk full")
+	lm.(*logManagerImpl).waitHandlersIdle(nil)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("send took %s with a 100ms timeout", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || errors.Is(reported[0], ErrHandlerTimeout) {
+		t.Errorf("reported = %v, want the webhook error", reported)
+	}
+}